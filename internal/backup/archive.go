@@ -0,0 +1,96 @@
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FormatVersion is the version of the archive layout written by Write.
+// it must be increased whenever a field is renamed or removed, so older
+// binaries refuse archives they can't fully restore.
+const FormatVersion = 1
+
+// Archive is the portable, database independent representation of a backup.
+// feeds and follows reference users and feeds by their ids inside the
+// archive, which are remapped on restore.
+type Archive struct {
+	Version     int          `json:"version"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Users       []User       `json:"users"`
+	Feeds       []Feed       `json:"feeds"`
+	FeedFollows []FeedFollow `json:"feed_follows"`
+}
+
+// User is a backed up row of the users table
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	IsAdmin   bool      `json:"is_admin,omitempty"` // missing in archives written before the admin role
+}
+
+// Feed is a backed up row of the feeds table
+type Feed struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	UserID        uuid.UUID  `json:"user_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+}
+
+// FeedFollow is a backed up row of the feed_follows table
+type FeedFollow struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    int32     `json:"feed_id"`
+}
+
+// Write encodes the archive as gzip compressed JSON.
+// returns an error if encoding or compressing fails
+func Write(w io.Writer, archive Archive) error {
+	zw := gzip.NewWriter(w)
+
+	err := json.NewEncoder(zw).Encode(archive)
+	if err != nil {
+		zw.Close()
+		return fmt.Errorf("error encoding archive: %w", err)
+	}
+
+	// flush the remaining compressed data
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error compressing archive: %w", err)
+	}
+
+	return nil
+}
+
+// Read decodes a gzip compressed JSON archive written by Write.
+// returns an error if the data is not a valid archive or was written
+// by a newer, unsupported format version
+func Read(r io.Reader) (Archive, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return Archive{}, fmt.Errorf("error decompressing archive: %w", err)
+	}
+	defer zr.Close()
+
+	var archive Archive
+	if err := json.NewDecoder(zr).Decode(&archive); err != nil {
+		return Archive{}, fmt.Errorf("error decoding archive: %w", err)
+	}
+
+	if archive.Version < 1 || archive.Version > FormatVersion {
+		return Archive{}, fmt.Errorf("unsupported archive version %d (supported up to %d)", archive.Version, FormatVersion)
+	}
+
+	return archive, nil
+}
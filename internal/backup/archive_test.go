@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteRead(t *testing.T) {
+	archive := Archive{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Users:     []User{{ID: uuid.New(), Name: "alice"}},
+		Feeds:     []Feed{{ID: 1, Name: "example", Url: "https://example.com/rss"}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, archive); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Version != archive.Version || !got.CreatedAt.Equal(archive.CreatedAt) {
+		t.Errorf("header = %v %v, want %v %v", got.Version, got.CreatedAt, archive.Version, archive.CreatedAt)
+	}
+	if len(got.Users) != 1 || got.Users[0].ID != archive.Users[0].ID {
+		t.Errorf("users = %v, want %v", got.Users, archive.Users)
+	}
+	if len(got.Feeds) != 1 || got.Feeds[0].Url != archive.Feeds[0].Url {
+		t.Errorf("feeds = %v, want %v", got.Feeds, archive.Feeds)
+	}
+}
+
+func TestReadRejectsUnknownVersion(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"version": 99}`))
+	zw.Close()
+
+	_, err := Read(&buf)
+	if err == nil || !strings.Contains(err.Error(), "unsupported archive version 99") {
+		t.Errorf("Read of version 99 = %v, want an unsupported version error", err)
+	}
+}
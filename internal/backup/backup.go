@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+// Dump reads users, feeds and feed follows from the store into an archive.
+// returns an error if any of the queries fails
+func Dump(ctx context.Context, st store.Store) (Archive, error) {
+	archive := Archive{
+		Version:   FormatVersion,
+		CreatedAt: time.Now(),
+	}
+
+	users, err := st.GetUsers(ctx)
+	if err != nil {
+		return Archive{}, fmt.Errorf("error getting users: %w", err)
+	}
+	for _, user := range users {
+		archive.Users = append(archive.Users, User{
+			ID:        user.ID,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+			Name:      user.Name,
+			IsAdmin:   user.IsAdmin,
+		})
+	}
+
+	feeds, err := st.ListFeeds(ctx)
+	if err != nil {
+		return Archive{}, fmt.Errorf("error getting feeds: %w", err)
+	}
+	for _, feed := range feeds {
+		f := Feed{
+			ID:        feed.ID,
+			Name:      feed.Name,
+			Url:       feed.Url,
+			UserID:    feed.UserID,
+			CreatedAt: feed.CreatedAt,
+			UpdatedAt: feed.UpdatedAt,
+		}
+		if feed.LastFetchedAt.Valid {
+			f.LastFetchedAt = &feed.LastFetchedAt.Time
+		}
+		archive.Feeds = append(archive.Feeds, f)
+	}
+
+	feedFollows, err := st.ListFeedFollows(ctx)
+	if err != nil {
+		return Archive{}, fmt.Errorf("error getting feed follows: %w", err)
+	}
+	for _, follow := range feedFollows {
+		archive.FeedFollows = append(archive.FeedFollows, FeedFollow{
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    follow.FeedID,
+		})
+	}
+
+	return archive, nil
+}
+
+// Restore loads an archive into the store inside a single transaction.
+// when replace is true all existing users (and, by cascade, their feeds and
+// follows) are deleted first; otherwise the archive is merged: users are
+// matched by id, then by name, feeds by url, and rows that already exist are kept.
+// if no user ends up an admin, the oldest one is promoted.
+// returns an error if any statement fails, in which case nothing is changed
+func Restore(ctx context.Context, st store.Store, archive Archive, replace bool) error {
+	return st.InTx(ctx, func(qtx store.Store) error {
+		return restore(ctx, qtx, archive, replace)
+	})
+}
+
+// restore does the work of Restore against a transactional store
+func restore(ctx context.Context, qtx store.Store, archive Archive, replace bool) error {
+	if replace {
+		if err := qtx.DeleteUsers(ctx); err != nil {
+			return fmt.Errorf("error deleting existing users: %w", err)
+		}
+	}
+
+	// archive ids mapped to the ids the rows have in this database
+	userIDs := map[uuid.UUID]uuid.UUID{}
+	feedIDs := map[int32]int32{}
+
+	for _, user := range archive.Users {
+		err := qtx.RestoreUser(ctx, database.RestoreUserParams{
+			ID:        user.ID,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+			Name:      user.Name,
+			IsAdmin:   user.IsAdmin,
+		})
+		if err != nil {
+			return fmt.Errorf("error restoring user %v: %w", user.Name, err)
+		}
+
+		// the user keeps its id even if it was renamed since the backup;
+		// otherwise an existing user with the same name keeps its own id
+		restored, err := qtx.GetUserByID(ctx, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			restored, err = qtx.GetUser(ctx, user.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting restored user %v: %w", user.Name, err)
+		}
+		userIDs[user.ID] = restored.ID
+	}
+
+	for _, feed := range archive.Feeds {
+		userID, ok := userIDs[feed.UserID]
+		if !ok {
+			return fmt.Errorf("feed %v references unknown user %v", feed.Url, feed.UserID)
+		}
+
+		lastFetchedAt := sql.NullTime{}
+		if feed.LastFetchedAt != nil {
+			lastFetchedAt = sql.NullTime{Time: *feed.LastFetchedAt, Valid: true}
+		}
+
+		err := qtx.RestoreFeed(ctx, database.RestoreFeedParams{
+			Name:          feed.Name,
+			Url:           feed.Url,
+			UserID:        userID,
+			CreatedAt:     feed.CreatedAt,
+			UpdatedAt:     feed.UpdatedAt,
+			LastFetchedAt: lastFetchedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error restoring feed %v: %w", feed.Url, err)
+		}
+
+		// feed ids are serial, so look up the id the feed got (or already had)
+		restored, err := qtx.GetFeedByUrl(ctx, feed.Url)
+		if err != nil {
+			return fmt.Errorf("error getting restored feed %v: %w", feed.Url, err)
+		}
+		feedIDs[feed.ID] = restored.ID
+	}
+
+	for _, follow := range archive.FeedFollows {
+		userID, ok := userIDs[follow.UserID]
+		if !ok {
+			return fmt.Errorf("feed follow references unknown user %v", follow.UserID)
+		}
+		feedID, ok := feedIDs[follow.FeedID]
+		if !ok {
+			return fmt.Errorf("feed follow references unknown feed %v", follow.FeedID)
+		}
+
+		err := qtx.RestoreFeedFollow(ctx, database.RestoreFeedFollowParams{
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    userID,
+			FeedID:    feedID,
+		})
+		if err != nil {
+			return fmt.Errorf("error restoring feed follow: %w", err)
+		}
+	}
+
+	return ensureAdmin(ctx, qtx)
+}
+
+// ensureAdmin promotes the oldest user when no user is an admin, as
+// migration 005 does, e.g. after restoring an archive written before
+// users had roles.
+// returns an error if the users can't be listed or updated
+func ensureAdmin(ctx context.Context, qtx store.Store) error {
+	users, err := qtx.GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	oldest := users[0]
+	for _, user := range users {
+		if user.IsAdmin {
+			return nil
+		}
+		if user.CreatedAt.Before(oldest.CreatedAt) {
+			oldest = user
+		}
+	}
+
+	_, err = qtx.SetUserAdmin(ctx, database.SetUserAdminParams{
+		Name:      oldest.Name,
+		IsAdmin:   true,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error promoting user %v: %w", oldest.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+// seed creates alice (the admin) with a followed feed and bob
+func seed(t *testing.T, st store.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	var alice database.User
+	for _, name := range []string{"alice", "bob"} {
+		user, err := st.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+		})
+		if err != nil {
+			t.Fatalf("creating user %v: %v", name, err)
+		}
+		if name == "alice" {
+			alice = user
+		}
+	}
+
+	feed, err := st.CreateFeed(ctx, database.CreateFeedParams{
+		Name:   "example",
+		Url:    "https://example.com/rss",
+		UserID: alice.ID,
+	})
+	if err != nil {
+		t.Fatalf("creating feed: %v", err)
+	}
+	_, err = st.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		UserID: alice.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		t.Fatalf("following feed: %v", err)
+	}
+}
+
+// userNames returns the names of the users in the store
+func userNames(t *testing.T, st store.Store) []string {
+	t.Helper()
+
+	users, err := st.GetUsers(context.Background())
+	if err != nil {
+		t.Fatalf("getting users: %v", err)
+	}
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func TestRestoreReplace(t *testing.T) {
+	ctx := context.Background()
+	src := store.NewMemory()
+	seed(t, src)
+
+	archive, err := Dump(ctx, src)
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+
+	dst := store.NewMemory()
+	seed(t, dst)
+	if err := Restore(ctx, dst, archive, true); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	users, _ := dst.GetUsers(ctx)
+	if len(users) != 2 || users[0].ID != archive.Users[0].ID {
+		t.Errorf("users after replace = %v, want the archived ones", users)
+	}
+	follows, _ := dst.ListFeedFollows(ctx)
+	if len(follows) != 1 || follows[0].UserID != archive.Users[0].ID {
+		t.Errorf("follows after replace = %v, want alice's follow", follows)
+	}
+}
+
+func TestRestoreRollsBack(t *testing.T) {
+	ctx := context.Background()
+	src := store.NewMemory()
+	seed(t, src)
+
+	archive, err := Dump(ctx, src)
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	// a follow of a feed that isn't in the archive fails the restore
+	// after the users and feeds were written
+	archive.FeedFollows = append(archive.FeedFollows, FeedFollow{
+		UserID: archive.Users[0].ID,
+		FeedID: 42,
+	})
+
+	dst := store.NewMemory()
+	if _, err := dst.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Name: "carol"}); err != nil {
+		t.Fatalf("creating carol: %v", err)
+	}
+
+	if err := Restore(ctx, dst, archive, true); err == nil {
+		t.Fatal("Restore of a broken archive succeeded")
+	}
+
+	if names := userNames(t, dst); len(names) != 1 || names[0] != "carol" {
+		t.Errorf("users after a failed restore = %v, want [carol]", names)
+	}
+	if feeds, _ := dst.ListFeeds(ctx); len(feeds) != 0 {
+		t.Errorf("feeds after a failed restore = %v, want none", feeds)
+	}
+}
+
+func TestRestoreMergeAfterRename(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemory()
+	seed(t, st)
+
+	archive, err := Dump(ctx, st)
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+
+	_, err = st.RenameUser(ctx, database.RenameUserParams{NewName: "alicia", OldName: "alice"})
+	if err != nil {
+		t.Fatalf("renaming alice: %v", err)
+	}
+
+	if err := Restore(ctx, st, archive, false); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	// alice is matched by id, so the new name stays and isn't duplicated
+	names := userNames(t, st)
+	if len(names) != 2 || names[0] != "alicia" || names[1] != "bob" {
+		t.Errorf("users after merge = %v, want [alicia bob]", names)
+	}
+	follows, _ := st.ListFeedFollows(ctx)
+	if len(follows) != 1 {
+		t.Errorf("follows after merge = %v, want one", follows)
+	}
+}
+
+func TestRestorePromotesOldestUser(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// an archive written before users had roles
+	archive := Archive{
+		Version: FormatVersion,
+		Users: []User{
+			{ID: uuid.New(), Name: "bob", CreatedAt: now},
+			{ID: uuid.New(), Name: "alice", CreatedAt: now.Add(-time.Hour)},
+		},
+	}
+
+	st := store.NewMemory()
+	seed(t, st)
+	if err := Restore(ctx, st, archive, true); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	users, _ := st.GetUsers(ctx)
+	for _, user := range users {
+		if user.IsAdmin != (user.Name == "alice") {
+			t.Errorf("user %v admin = %v, only the oldest user should be an admin", user.Name, user.IsAdmin)
+		}
+	}
+}
@@ -1,37 +1,266 @@
 package cli
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
 
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/output"
 	"github.com/luis-octavius/blog-aggregator/internal/types"
 )
 
-// Commands represents a registry of available CLI commands. 
-// It maps command names to their corresponding handler functions. 
+// Commands represents a registry of available CLI commands.
+// It maps command names to their corresponding handler functions,
+// and keeps the metadata used for help and argument validation.
 type Commands struct {
 	Commands map[string]func(*types.State, Command) error
+	info     map[string]CommandInfo
+
+	// BeforeRun, if set, runs once the arguments of a command are valid
+	// and before its handler, e.g. to check the database schema.
+	// an error stops the command.
+	BeforeRun func(s *types.State, name string, info CommandInfo) error
+}
+
+// CommandInfo describes a registered command
+type CommandInfo struct {
+	Usage   string // arguments after the command name, e.g. "<name> <url>"
+	Summary string // one line description shown by help
+
+	// number of arguments left after flags are parsed;
+	// MaxArgs < 0 allows any number
+	MinArgs int
+	MaxArgs int
+
+	// Flags defines the command's flags on fs, e.g. fs.Bool("replace", ...).
+	// the parsed flags are available to the handler through Command.Flags
+	Flags func(fs *flag.FlagSet)
+
+	// Complete, if set, returns the candidates for the next positional
+	// argument given the ones before it, for shell completion
+	Complete func(s *types.State, args []string) ([]string, error)
+
+	// Output marks commands that print their results in the format chosen
+	// with --output; the others only run with the text format
+	Output bool
+
+	// LoginRequired is set by RegisterLoggedIn for commands that
+	// run as the current user
+	LoginRequired bool
+
+	// SkipSchemaCheck lets the command run against a database whose
+	// schema doesn't match the embedded migrations, for commands that
+	// fix or inspect the setup
+	SkipSchemaCheck bool
 }
 
 // Run executes a command by looking up its name in the registry.
-// returns an error if the command doesn't exist or the handler function fails. 
+// the command's flags are parsed (they may appear before, between or
+// after the arguments; "--" ends them) and the number of arguments is
+// checked before BeforeRun and the handler run. -h or --help prints the
+// command's help.
+// returns an *ExitError if the command doesn't exist, its arguments are
+// invalid, it has no output in the chosen format or the handler function
+// fails, carrying the exit code for the error (see ExitCode) and a message
+// naming the command.
 func (c *Commands) Run(s *types.State, cmd Command) error {
-	// lookup command in registry, return error if not registered 
+	// lookup command in registry, return error if not registered
 	command, ok := c.Commands[cmd.Name]
 	if !ok {
-		return fmt.Errorf("command not found: %s", cmd.Name)
+		err := fmt.Errorf("%w: %s, run help to list commands", ErrUnknownCommand, cmd.Name)
+		return &ExitError{Code: ExitCode(err), Err: err}
+	}
+
+	// parse flags and validate arguments before dispatch
+	info := c.info[cmd.Name]
+	err := info.parse(&cmd)
+	if errors.Is(err, flag.ErrHelp) {
+		c.printHelp(os.Stdout, cmd.Name)
+		return nil
+	}
+	if err != nil {
+		fmt.Printf("Usage: go run . %v\n", usageLine(cmd.Name, info))
+		return &ExitError{
+			Code: ExitCode(err),
+			Err:  fmt.Errorf("command %s failed: %w", cmd.Name, err),
+		}
+	}
+
+	if s.Output != output.Text && s.Output != "" && !info.Output {
+		err := fmt.Errorf("%w: no %v output, use --output text", ErrInvalidArgs, s.Output)
+		return &ExitError{
+			Code: ExitCode(err),
+			Err:  fmt.Errorf("command %s failed: %w", cmd.Name, err),
+		}
+	}
+
+	if c.BeforeRun != nil {
+		if err := c.BeforeRun(s, cmd.Name, info); err != nil {
+			return &ExitError{Code: ExitCode(err), Err: err}
+		}
 	}
 
-	// execute the command handler with provided state and arguments  
-	err := command(s, cmd)
+	// execute the command handler with provided state and arguments
+	err = command(s, cmd)
 	if err != nil {
-		return fmt.Errorf("command %s failed: %w", cmd.Name, err)
+		return &ExitError{
+			Code: ExitCode(err),
+			Err:  fmt.Errorf("command %s failed: %w", cmd.Name, err),
+		}
 	}
 	return nil
 }
 
-// Register adds a new command to the registry. 
+// Register adds a new command to the registry.
 // This allows dynamic registration of commmand handlers at runtime.
-func (c *Commands) Register(name string, f func(*types.State, Command) error) error {
+// returns an error if a command with the same name is already registered
+func (c *Commands) Register(name string, info CommandInfo, f func(*types.State, Command) error) error {
+	if _, ok := c.Commands[name]; ok {
+		return fmt.Errorf("command %v is already registered", name)
+	}
+	if c.Commands == nil {
+		c.Commands = map[string]func(*types.State, Command) error{}
+	}
+	if c.info == nil {
+		c.info = map[string]CommandInfo{}
+	}
+
 	c.Commands[name] = f
+	c.info[name] = info
+	return nil
+}
+
+// RegisterLoggedIn adds a command that runs as the current user,
+// wrapping its handler with MiddlewareLoggedIn
+// returns an error if a command with the same name is already registered
+func (c *Commands) RegisterLoggedIn(name string, info CommandInfo, f func(*types.State, Command, database.User) error) error {
+	info.LoginRequired = true
+	return c.Register(name, info, MiddlewareLoggedIn(f))
+}
+
+// Lookup returns the metadata of a registered command
+func (c *Commands) Lookup(name string) (CommandInfo, bool) {
+	info, ok := c.info[name]
+	return info, ok
+}
+
+// Names returns the names of all registered commands, sorted, leaving
+// out hidden commands (their names start with "__")
+func (c *Commands) Names() []string {
+	names := make([]string, 0, len(c.Commands))
+	for name := range c.Commands {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HandlerHelp lists every command with its summary, or with a command
+// name prints its usage, summary and flags
+//
+// returns an error if the command doesn't exist (ErrUnknownCommand)
+func (c *Commands) HandlerHelp(s *types.State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		fmt.Println("Usage: go run . [--config file] [--profile name] [--db-url url] [--user name] [--output text|table|json|yaml] <command> [args]")
+		fmt.Println("")
+		fmt.Println("Commands:")
+		for _, name := range c.Names() {
+			fmt.Printf("  %-10s %v\n", name, c.info[name].Summary)
+		}
+		fmt.Println("")
+		fmt.Println("Run `go run . help <command>` for the usage of a command.")
+		return nil
+	}
+
+	name := cmd.Args[0]
+	if _, ok := c.Commands[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+
+	c.printHelp(os.Stdout, name)
+	return nil
+}
+
+// printHelp writes the usage, summary and flags of a command to w
+func (c *Commands) printHelp(w io.Writer, name string) {
+	info := c.info[name]
+
+	fmt.Fprintf(w, "Usage: go run . %v\n", usageLine(name, info))
+	if info.Summary != "" {
+		fmt.Fprintf(w, "\n%v\n", info.Summary)
+	}
+	if info.LoginRequired {
+		fmt.Fprintln(w, "\nRuns as the current user, see login.")
+	}
+
+	if info.Flags != nil {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		info.Flags(fs)
+		fs.SetOutput(w)
+		fmt.Fprintln(w, "\nFlags:")
+		fs.PrintDefaults()
+	}
+}
+
+// usageLine returns the command name followed by its usage
+func usageLine(name string, info CommandInfo) string {
+	if info.Usage == "" {
+		return name
+	}
+	return name + " " + info.Usage
+}
+
+// parse parses the command's flags out of cmd.Args, leaving only the
+// positional arguments, and checks how many are left
+// returns flag.ErrHelp if help was requested, or an error wrapping
+// ErrInvalidArgs if a flag is unknown or the number of arguments is wrong
+func (info CommandInfo) parse(cmd *Command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if info.Flags != nil {
+		info.Flags(fs)
+	}
+
+	args := cmd.Args
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return fmt.Errorf("%w: %w", ErrInvalidArgs, err)
+		}
+
+		// Parse stops at the first non-flag argument or right after "--";
+		// everything after "--" is positional
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := args[:len(args)-len(rest)]; len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	cmd.Args = positional
+	cmd.Flags = fs
+
+	switch {
+	case len(cmd.Args) < info.MinArgs:
+		return fmt.Errorf("%w: missing arguments", ErrInvalidArgs)
+	case info.MaxArgs >= 0 && len(cmd.Args) > info.MaxArgs:
+		return fmt.Errorf("%w: too many arguments", ErrInvalidArgs)
+	}
+
 	return nil
 }
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"slices"
+	"testing"
+
+	"github.com/luis-octavius/blog-aggregator/internal/types"
+)
+
+func TestCommandInfoParse(t *testing.T) {
+	info := CommandInfo{
+		MinArgs: 1, MaxArgs: 2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("replace", false, "")
+			fs.String("name", "", "")
+		},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		replace bool
+		flag    string
+		err     error
+	}{
+		{name: "flags first", args: []string{"--replace", "file"}, want: []string{"file"}, replace: true},
+		{name: "flags last", args: []string{"file", "--replace"}, want: []string{"file"}, replace: true},
+		{name: "flags between", args: []string{"a", "--name", "x", "b"}, want: []string{"a", "b"}, flag: "x"},
+		{name: "flag with =", args: []string{"a", "-name=x"}, want: []string{"a"}, flag: "x"},
+		{name: "double dash ends flags", args: []string{"a", "--", "--replace"}, want: []string{"a", "--replace"}},
+		{name: "missing arguments", args: []string{"--replace"}, err: ErrInvalidArgs},
+		{name: "too many arguments", args: []string{"a", "b", "c"}, err: ErrInvalidArgs},
+		{name: "unknown flag", args: []string{"a", "--force"}, err: ErrInvalidArgs},
+		{name: "help", args: []string{"a", "-h"}, err: flag.ErrHelp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := Command{Name: "test", Args: tt.args}
+			err := info.parse(&cmd)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("parse(%q) error = %v, want %v", tt.args, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse(%q): %v", tt.args, err)
+			}
+
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("parse(%q) args = %q, want %q", tt.args, cmd.Args, tt.want)
+			}
+			if cmd.Bool("replace") != tt.replace {
+				t.Errorf("parse(%q) replace = %v, want %v", tt.args, cmd.Bool("replace"), tt.replace)
+			}
+			if got := cmd.Flags.Lookup("name").Value.String(); got != tt.flag {
+				t.Errorf("parse(%q) name = %q, want %q", tt.args, got, tt.flag)
+			}
+		})
+	}
+}
+
+func TestCommandInfoParseAnyNumber(t *testing.T) {
+	cmd := Command{Name: "test", Args: []string{"a", "b", "c", "d"}}
+	if err := (CommandInfo{MaxArgs: -1}).parse(&cmd); err != nil {
+		t.Errorf("parse with MaxArgs -1: %v", err)
+	}
+}
+
+func TestRunValidatesBeforeDispatch(t *testing.T) {
+	s := newTestState(t)
+
+	c := Commands{}
+	called := false
+	c.Register("one", CommandInfo{MinArgs: 1, MaxArgs: 1}, func(s *types.State, cmd Command) error {
+		called = true
+		return nil
+	})
+
+	err := c.Run(s, Command{Name: "one"})
+	if ExitCode(err) != ExitUsage || called {
+		t.Errorf("running without its argument: got %v (exit %d), called %v", err, ExitCode(err), called)
+	}
+	err = c.Run(s, Command{Name: "missing"})
+	if !errors.Is(err, ErrUnknownCommand) || ExitCode(err) != ExitUsage {
+		t.Errorf("running an unknown command: got %v, want ErrUnknownCommand", err)
+	}
+	if err := c.Run(s, Command{Name: "one", Args: []string{"a"}}); err != nil || !called {
+		t.Errorf("running with its argument: got %v, called %v", err, called)
+	}
+	if err := c.Register("one", CommandInfo{}, nil); err == nil {
+		t.Error("registering one twice succeeded")
+	}
+}
@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/luis-octavius/blog-aggregator/internal/types"
+)
+
+// completeCommand is the hidden command the completion scripts call back
+// into to complete arguments that depend on the database
+const completeCommand = "__complete"
+
+// HandlerCompletion prints a completion script for bash, zsh or fish,
+// generated from the registered commands. load it with e.g.
+//
+//	source <(gator completion bash)
+//	gator completion fish > ~/.config/fish/completions/gator.fish
+//
+// returns an error if the shell is not supported (ErrInvalidArgs)
+func (c *Commands) HandlerCompletion(s *types.State, cmd Command) error {
+	switch cmd.Args[0] {
+	case "bash":
+		c.writeBashCompletion()
+	case "zsh":
+		c.writeZshCompletion()
+	case "fish":
+		c.writeFishCompletion()
+	default:
+		return fmt.Errorf("%w: unsupported shell %v, use bash, zsh or fish", ErrInvalidArgs, cmd.Args[0])
+	}
+
+	return nil
+}
+
+// HandlerComplete prints the candidates for the last of the given words,
+// one per line. the words are the command line from the command name on,
+// the last one being the (possibly empty) word to complete. the scripts
+// pass the global flags of the command line before __complete, so they
+// apply to the lookups too. lookups that fail print nothing, so a shell
+// never shows errors while completing.
+func (c *Commands) HandlerComplete(s *types.State, cmd Command) error {
+	for _, candidate := range c.complete(s, cmd.Args) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// complete returns the candidates for the last of words, which starts
+// with the command name. candidates come from the command's flags when the
+// word starts with "-", otherwise from its Complete function.
+func (c *Commands) complete(s *types.State, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	current := words[len(words)-1]
+
+	// complete the command name
+	if len(words) == 1 {
+		return withPrefix(c.Names(), current)
+	}
+
+	info, ok := c.info[words[0]]
+	if !ok {
+		return nil
+	}
+
+	// complete the command's flags
+	if strings.HasPrefix(current, "-") {
+		if info.Flags == nil {
+			return nil
+		}
+		fs := flag.NewFlagSet(words[0], flag.ContinueOnError)
+		info.Flags(fs)
+
+		var flags []string
+		fs.VisitAll(func(f *flag.Flag) {
+			flags = append(flags, "--"+f.Name)
+		})
+		return withPrefix(flags, current)
+	}
+
+	// complete positional arguments, leaving flags out of the count
+	if info.Complete == nil {
+		return nil
+	}
+
+	var args []string
+	for _, word := range words[1 : len(words)-1] {
+		if !strings.HasPrefix(word, "-") {
+			args = append(args, word)
+		}
+	}
+
+	candidates, err := info.Complete(s, args)
+	if err != nil {
+		return nil
+	}
+	return withPrefix(candidates, current)
+}
+
+// CompleteCommands completes the name of a registered command
+func (c *Commands) CompleteCommands(s *types.State, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return nil, nil
+	}
+	return c.Names(), nil
+}
+
+// CompleteWords returns a Complete function that offers the given
+// words for the first argument, e.g. a command's subcommands
+func CompleteWords(words ...string) func(*types.State, []string) ([]string, error) {
+	return func(s *types.State, args []string) ([]string, error) {
+		if len(args) > 0 {
+			return nil, nil
+		}
+		return words, nil
+	}
+}
+
+// CompleteProfile completes the subcommands of profile, and the
+// profile names for use and remove
+func CompleteProfile(s *types.State, args []string) ([]string, error) {
+	switch {
+	case len(args) == 0:
+		return []string{"list", "use", "add", "remove"}, nil
+	case len(args) == 1 && (args[0] == "use" || args[0] == "remove"):
+		return s.Config.ProfileNames(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// CompleteUserCommand completes the subcommands of user, and the
+// name of the user they change
+func CompleteUserCommand(s *types.State, args []string) ([]string, error) {
+	switch len(args) {
+	case 0:
+		return []string{"delete", "rename", "promote", "demote"}, nil
+	case 1:
+		return CompleteUsers(s, nil)
+	default:
+		return nil, nil
+	}
+}
+
+// CompleteUsers completes the first argument with the names of all users
+func CompleteUsers(s *types.State, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return nil, nil
+	}
+
+	users, err := s.Db.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names, nil
+}
+
+// CompleteFeedUrls completes the first argument with the urls of all feeds
+func CompleteFeedUrls(s *types.State, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return nil, nil
+	}
+
+	feeds, err := s.Db.ListFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url)
+	}
+	return urls, nil
+}
+
+// CompleteFollowedFeedUrls completes the first argument with the urls of
+// the feeds the current user follows
+func CompleteFollowedFeedUrls(s *types.State, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+
+	user, err := s.Db.GetUser(ctx, s.Config.Current_user_name)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.Db.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.Db.ListFeedFollows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urlByID := map[int32]string{}
+	for _, feed := range feeds {
+		urlByID[feed.ID] = feed.Url
+	}
+
+	var urls []string
+	for _, follow := range follows {
+		if follow.UserID == user.ID {
+			urls = append(urls, urlByID[follow.FeedID])
+		}
+	}
+	return urls, nil
+}
+
+// withPrefix returns the candidates starting with prefix
+func withPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// writeBashCompletion prints the bash completion script. command names are
+// listed in the script; arguments are completed by calling back into the
+// binary with the global flags of the command line (e.g. --profile), falling
+// back to file names when it has no candidates.
+func (c *Commands) writeBashCompletion() {
+	fmt.Printf(`# bash completion for gator, generated by "gator completion bash"
+_gator() {
+	local cur words cword
+	if declare -F _get_comp_words_by_ref >/dev/null; then
+		# keep urls in one word, ":" splits words by default
+		_get_comp_words_by_ref -n : cur words cword
+	else
+		cur="${COMP_WORDS[COMP_CWORD]}"
+		words=("${COMP_WORDS[@]}")
+		cword=$COMP_CWORD
+	fi
+
+	# global flags and their values come before the command name
+	local i=1
+	local -a globals=()
+	while [[ $i -lt $cword && ${words[i]} == -* ]]; do
+		if [[ ${words[i]} == *=* ]]; then
+			globals+=("${words[i]}")
+			i=$((i + 1))
+		else
+			globals+=("${words[i]}" "${words[i + 1]}")
+			i=$((i + 2))
+		fi
+	done
+
+	local IFS=$'\n'
+	if [[ $i -gt $cword ]]; then
+		# the value of a global flag
+		return
+	elif [[ $i -eq $cword ]]; then
+		COMPREPLY=($(compgen -W %s -- "$cur"))
+	else
+		COMPREPLY=($("${words[0]}" "${globals[@]}" %s -- "${words[@]:i:cword-i+1}" 2>/dev/null))
+	fi
+
+	if declare -F __ltrim_colon_completions >/dev/null; then
+		__ltrim_colon_completions "$cur"
+	fi
+}
+complete -o default -F _gator gator
+`, shellQuote(strings.Join(c.Names(), "\n")), completeCommand)
+}
+
+// writeZshCompletion prints the zsh completion script, describing each
+// command with its summary. like the bash script it passes the global
+// flags of the command line to the binary.
+func (c *Commands) writeZshCompletion() {
+	fmt.Println(`#compdef gator
+# zsh completion for gator, generated by "gator completion zsh"
+_gator() {
+	local -a commands candidates
+	commands=(`)
+	for _, name := range c.Names() {
+		fmt.Printf("\t\t%v\n", shellQuote(name+":"+c.info[name].Summary))
+	}
+	fmt.Printf(`	)
+
+	# global flags and their values come before the command name
+	local i=2
+	local -a globals
+	while (( i < CURRENT )) && [[ ${words[i]} == -* ]]; do
+		if [[ ${words[i]} == *=* ]]; then
+			globals+=("${words[i]}")
+			(( i += 1 ))
+		else
+			globals+=("${words[i]}" "${words[i+1]}")
+			(( i += 2 ))
+		fi
+	done
+
+	if (( i > CURRENT )); then
+		# the value of a global flag
+		_files
+		return
+	fi
+	if (( i == CURRENT )); then
+		_describe 'command' commands
+		return
+	fi
+
+	candidates=("${(@f)$("${words[1]}" "${globals[@]}" %s -- "${(@)words[i,CURRENT]}" 2>/dev/null)}")
+	if [[ -n ${candidates[1]} ]]; then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+compdef _gator gator
+`, completeCommand)
+}
+
+// writeFishCompletion prints the fish completion script. commands with
+// a Complete function only offer its candidates; the others also offer
+// file names, as fish does by default. like the bash script it passes the
+// global flags of the command line to the binary.
+func (c *Commands) writeFishCompletion() {
+	fmt.Print(`# fish completion for gator, generated by "gator completion fish"
+function __gator_complete
+	set -l words (commandline -opc) (commandline -ct)
+
+	# global flags and their values come before the command name
+	set -l globals
+	set -l i 2
+	while test $i -lt (count $words); and string match -q -- '-*' $words[$i]
+		if string match -q -- '*=*' $words[$i]
+			set -a globals $words[$i]
+			set i (math $i + 1)
+		else
+			set -a globals $words[$i] $words[(math $i + 1)]
+			set i (math $i + 2)
+		end
+	end
+	if test $i -ge (count $words)
+		return
+	end
+
+	$words[1] $globals ` + completeCommand + ` -- $words[$i..-1] 2>/dev/null
+end
+
+`)
+	for _, name := range c.Names() {
+		fmt.Printf("complete -c gator -f -n __fish_use_subcommand -a %v -d %v\n", name, shellQuote(c.info[name].Summary))
+	}
+	fmt.Println("")
+	for _, name := range c.Names() {
+		info := c.info[name]
+		if info.Complete == nil && info.Flags == nil {
+			continue
+		}
+		noFiles := ""
+		if info.Complete != nil {
+			noFiles = " -f"
+		}
+		fmt.Printf("complete -c gator%v -n '__fish_seen_subcommand_from %v' -a '(__gator_complete)'\n", noFiles, name)
+	}
+}
+
+// shellQuote quotes s as a single-quoted shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
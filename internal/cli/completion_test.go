@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"slices"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	s := newTestState(t)
+	for _, name := range []string{"alice", "bob"} {
+		if err := run(s, HandlerRegister, "register", name); err != nil {
+			t.Fatalf("register %v: %v", name, err)
+		}
+	}
+	addFeed(t, s, "bobs", "https://bob.example/rss")
+	if err := run(s, HandlerLogin, "login", "alice"); err != nil {
+		t.Fatalf("login alice: %v", err)
+	}
+	addFeed(t, s, "alices", "https://alice.example/rss")
+
+	c := Commands{}
+	c.Register("login", CommandInfo{Complete: CompleteUsers}, HandlerLogin)
+	c.Register("follow", CommandInfo{Complete: CompleteFeedUrls}, nil)
+	c.Register("unfollow", CommandInfo{Complete: CompleteFollowedFeedUrls}, nil)
+	c.Register("user", CommandInfo{Complete: CompleteUserCommand}, nil)
+	c.Register("restore", CommandInfo{
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("replace", false, "")
+			ConfirmFlag(fs)
+		},
+	}, nil)
+
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{name: "command names", words: []string{"u"}, want: []string{"unfollow", "user"}},
+		{name: "no words", words: nil, want: []string{"follow", "login", "restore", "unfollow", "user"}},
+		{name: "users", words: []string{"login", ""}, want: []string{"alice", "bob"}},
+		{name: "users with prefix", words: []string{"login", "b"}, want: []string{"bob"}},
+		{name: "only the first argument", words: []string{"login", "alice", ""}, want: nil},
+		{name: "every feed", words: []string{"follow", "https://b"}, want: []string{"https://bob.example/rss"}},
+		{name: "followed feeds", words: []string{"unfollow", ""}, want: []string{"https://alice.example/rss"}},
+		{name: "subcommands", words: []string{"user", "d"}, want: []string{"delete", "demote"}},
+		{name: "subcommand argument", words: []string{"user", "rename", "a"}, want: []string{"alice"}},
+		{name: "flags", words: []string{"restore", "--"}, want: []string{"--replace", "--" + confirmFlag}},
+		{name: "flags are not arguments", words: []string{"user", "--" + confirmFlag, "delete", "b"}, want: []string{"bob"}},
+		{name: "unknown command", words: []string{"missing", ""}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.complete(s, tt.words)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("complete(%q) = %q, want %q", tt.words, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerCompletionRejectsUnknownShell(t *testing.T) {
+	s := newTestState(t)
+
+	c := Commands{}
+	err := c.HandlerCompletion(s, Command{Name: "completion", Args: []string{"powershell"}})
+	if !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("completion powershell: got %v, want ErrInvalidArgs", err)
+	}
+}
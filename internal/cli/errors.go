@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+)
+
+// errors returned by handlers, wrapped with details like the user name
+// or feed url. Commands.Run maps them to the exit codes below.
+var (
+	ErrInvalidArgs    = errors.New("invalid arguments")
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrFeedNotFound   = errors.New("feed not found")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrNotLoggedIn    = errors.New("not logged in")
+	ErrNotAllowed     = errors.New("not allowed")
+)
+
+// exit codes of the gator binary:
+// - 0: the command succeeded
+// - 1: the command failed for another reason (database, network, files)
+// - 2: the command or its arguments are invalid
+// - 3: the user or feed the command refers to doesn't exist
+// - 4: the user, feed or follow the command creates already exists
+// - 5: the command requires a logged in user and there is none
+// - 6: the current user isn't allowed to run the command (e.g. reset)
+const (
+	ExitOK            = 0
+	ExitFailure       = 1
+	ExitUsage         = 2
+	ExitNotFound      = 3
+	ExitAlreadyExists = 4
+	ExitNotLoggedIn   = 5
+	ExitNotAllowed    = 6
+)
+
+// ExitError is a failed command together with the exit code it maps to
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for an error returned by a command,
+// ExitOK for nil and ExitFailure for errors without a more specific code
+func ExitCode(err error) int {
+	var exitErr *ExitError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.Is(err, ErrInvalidArgs), errors.Is(err, ErrUnknownCommand):
+		return ExitUsage
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrFeedNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrAlreadyExists):
+		return ExitAlreadyExists
+	case errors.Is(err, ErrNotLoggedIn):
+		return ExitNotLoggedIn
+	case errors.Is(err, ErrNotAllowed):
+		return ExitNotAllowed
+	default:
+		return ExitFailure
+	}
+}
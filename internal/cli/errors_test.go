@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("connection refused"), ExitFailure},
+		{fmt.Errorf("%w: missing url", ErrInvalidArgs), ExitUsage},
+		{ErrUnknownCommand, ExitUsage},
+		{fmt.Errorf("%w: alice", ErrUserNotFound), ExitNotFound},
+		{fmt.Errorf("%w: https://example.com/rss", ErrFeedNotFound), ExitNotFound},
+		{fmt.Errorf("user alice %w", ErrAlreadyExists), ExitAlreadyExists},
+		{ErrNotLoggedIn, ExitNotLoggedIn},
+		{ErrNotAllowed, ExitNotAllowed},
+		{&ExitError{Code: ExitUsage, Err: errors.New("wrapped")}, ExitUsage},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
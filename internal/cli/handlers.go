@@ -2,62 +2,96 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/luis-octavius/blog-aggregator/internal/backup"
+	"github.com/luis-octavius/blog-aggregator/internal/config"
 	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/feed"
+	"github.com/luis-octavius/blog-aggregator/internal/migrate"
+	"github.com/luis-octavius/blog-aggregator/internal/output"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
 	"github.com/luis-octavius/blog-aggregator/internal/types"
 )
 
 // Command represents a CLI command 
 type Command struct {
-	Name string
-	Args []string
+	Name  string
+	Args  []string
+	Flags *flag.FlagSet // flags parsed out of Args by Commands.Run
+}
+
+// Bool returns the value of a boolean flag of the command, 
+// false if the flag wasn't given or isn't defined 
+func (cmd Command) Bool(name string) bool {
+	if cmd.Flags == nil {
+		return false
+	}
+	f := cmd.Flags.Lookup(name)
+	if f == nil {
+		return false
+	}
+	value, ok := f.Value.(flag.Getter).Get().(bool)
+	return ok && value
 }
 
 // HandlerLogin authenticates a user by username and sets them as the current user.
 // it validates command-line arguments, checks user existence in the database,
 // and updates the configuration with the authenticated user.
-// returns an error if username is not provided, user doesn't exist, or config update fails. 
+// returns an error if the user doesn't exist (ErrUserNotFound) or config 
+// update fails. 
 func HandlerLogin(s *types.State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		fmt.Println("Usage: go run . login <username>")
-		return fmt.Errorf("username not provided")
-	}
-
 	name := cmd.Args[0]
 	ctx := context.Background()
 	queries := s.Db
 
 	// verify if user exists in database 
-	_, err := queries.GetUser(ctx, name)
+	user, err := queries.GetUser(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %v", ErrUserNotFound, name)
+	}
 	if err != nil {
-		fmt.Printf("the user %v does not exist\n", name)
-		os.Exit(1)
+		return fmt.Errorf("error getting user %v: %w", name, err)
 	}
 
 	// update configuration with authenticated user 
 	err = s.Config.SetUser(name)
 	if err != nil {
-		return fmt.Errorf("error setting user %v: %v", name, err)
+		return fmt.Errorf("error setting user %v: %w", name, err)
 	}
 
-	fmt.Printf("username %v has been set\n", name)
-	return nil
+	return output.RenderOne(os.Stdout, s.Output, user, output.List[database.User]{
+		Columns: userColumns,
+		Text: func(w io.Writer, users []database.User) {
+			fmt.Fprintf(w, "username %v has been set\n", name)
+		},
+	})
+}
+
+// userColumns describe a single user in the results of login and register 
+var userColumns = []output.Column[database.User]{
+	{Key: "name", Value: func(user database.User) any { return user.Name }},
+	{Key: "admin", Value: func(user database.User) any { return user.IsAdmin }},
+	{Key: "id", Value: func(user database.User) any { return user.ID }},
+	{Key: "created_at", Value: func(user database.User) any { return user.CreatedAt }},
+	{Key: "updated_at", Value: func(user database.User) any { return user.UpdatedAt }},
 }
 
 // HandlerRegister creates a new user in the database and sets them as the current user. 
-// if the username already exists, the operation fails and the program exits. 
-// returns an error if username is not provided or user creation fails.
+// returns an error if the username is already taken (ErrAlreadyExists) or 
+// user creation fails.
 func HandlerRegister(s *types.State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		fmt.Println("Usage: go run . register <username>")
-		return fmt.Errorf("username not provided")
-	}
-
 	name := cmd.Args[0]
 	ctx := context.Background()
 	queries := s.Db
@@ -70,44 +104,91 @@ func HandlerRegister(s *types.State, cmd Command) error {
 		UpdatedAt: time.Now(),
 		Name:      name,
 	})
+	if store.IsDuplicate(err) {
+		return fmt.Errorf("user %v %w", name, ErrAlreadyExists)
+	}
 	if err != nil {
-		fmt.Printf("the user %v already exists: %v\n", name, err)
-		os.Exit(1)
+		return fmt.Errorf("error creating user %v: %w", name, err)
 	}
 
 	// update configuration with authenticated user 
 	err = s.Config.SetUser(name)
 	if err != nil {
-		return fmt.Errorf("error setting user %v: %v", name, err)
+		return fmt.Errorf("error setting user %v: %w", name, err)
 	}
 
-	fmt.Printf("user %v was created\n", name)
-
-	// logs info about the user created for debugging 
-	fmt.Printf("User: %v\nCreatedAt: %v\nUpdated At: %v\nName: %v\n", insertedUser.ID, insertedUser.CreatedAt, insertedUser.UpdatedAt, insertedUser.Name)
+	return output.RenderOne(os.Stdout, s.Output, insertedUser, output.List[database.User]{
+		Columns: userColumns,
+		Text: func(w io.Writer, users []database.User) {
+			fmt.Fprintf(w, "user %v was created\n", name)
 
-	return nil
+			// logs info about the user created for debugging 
+			fmt.Fprintf(w, "User: %v\nCreatedAt: %v\nUpdated At: %v\nName: %v\n", insertedUser.ID, insertedUser.CreatedAt, insertedUser.UpdatedAt, insertedUser.Name)
+		},
+	})
 }
 
 // HandlerDelete remove all user records from the database. 
-// this is a destructive operation intended for reset purpose. 
-func HandlerDelete(s *types.State, cmd Command) error {
+// this is a destructive operation intended for reset purpose, 
+// so only admins can run it, and only with --yes-i-am-sure. 
+// 
+// returns an error if: 
+// - the current user is not an admin (ErrNotAllowed) 
+// - --yes-i-am-sure is missing (ErrInvalidArgs) 
+// - the deletion fails 
+func HandlerDelete(s *types.State, cmd Command, user database.User) error {
+	if !user.IsAdmin {
+		return fmt.Errorf("%w: only admins can reset the database", ErrNotAllowed)
+	}
+	if !cmd.Bool(confirmFlag) {
+		return fmt.Errorf("%w: reset deletes every user, feed and follow, run it again with --%v", ErrInvalidArgs, confirmFlag)
+	}
+
 	ctx := context.Background()
 	queries := s.Db
 
-	// execute deletion 
-	err := queries.DeleteUsers(ctx)
+	// count what goes away in the same transaction as the deletion 
+	var result resetResult
+	err := queries.InTx(ctx, func(tx store.Store) error {
+		archive, err := backup.Dump(ctx, tx)
+		if err != nil {
+			return err
+		}
+		result = resetResult{
+			Users:   len(archive.Users),
+			Feeds:   len(archive.Feeds),
+			Follows: len(archive.FeedFollows),
+		}
+
+		// execute deletion 
+		return tx.DeleteUsers(ctx)
+	})
 	if err != nil {
-		return fmt.Errorf("error deleting users: %v", err)
+		return fmt.Errorf("error deleting users: %w", err)
 	}
 
-	fmt.Println("rows succesfully deleted")
-	return nil
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[resetResult]{
+		Columns: []output.Column[resetResult]{
+			{Key: "users", Value: func(result resetResult) any { return result.Users }},
+			{Key: "feeds", Value: func(result resetResult) any { return result.Feeds }},
+			{Key: "follows", Value: func(result resetResult) any { return result.Follows }},
+		},
+		Text: func(w io.Writer, results []resetResult) {
+			fmt.Fprintln(w, "rows succesfully deleted")
+		},
+	})
+}
+
+// resetResult counts the records deleted by reset 
+type resetResult struct {
+	Users   int
+	Feeds   int
+	Follows int
 }
 
 // HandlerUsers lists all users from the database and displays their status. 
-// it highlights the currently authenticated user with a special marker. 
-// returns an error if the database query fails 
+// it highlights the currently authenticated user and admins with markers. 
+// returns an error if the database query or printing fails 
 func HandlerUsers(s *types.State, cmd Command) error {
 	ctx := context.Background() 
 	queries := s.Db 
@@ -118,39 +199,258 @@ func HandlerUsers(s *types.State, cmd Command) error {
 		return fmt.Errorf("error getting users from database: %w", err)
 	}
 
-	if len(users) == 0 {
+	// scripts asking for json or yaml get an empty list instead 
+	if len(users) == 0 && s.Output == output.Text {
 		return fmt.Errorf("no users on the database")
 	}
 
 	// get currently authenticated user from configuration 
 	currentUser := s.Config.Current_user_name
 
-	// display users with visual indicator for current user 
-	for _, user := range users {
-		if currentUser == user.Name {
-			fmt.Printf(" - %s (current)\n", user.Name)
-		} else {
-			fmt.Printf(" - %s\n", user.Name)
+	return output.Render(os.Stdout, s.Output, users, output.List[database.User]{
+		Columns: []output.Column[database.User]{
+			{Key: "name", Value: func(user database.User) any { return user.Name }},
+			{Key: "current", Value: func(user database.User) any { return user.Name == currentUser }},
+			{Key: "admin", Value: func(user database.User) any { return user.IsAdmin }},
+			{Key: "id", Value: func(user database.User) any { return user.ID }},
+			{Key: "created_at", Value: func(user database.User) any { return user.CreatedAt }},
+		},
+		// display users with visual indicators for current user and admins 
+		Text: func(w io.Writer, users []database.User) {
+			for _, user := range users {
+				var markers []string
+				if currentUser == user.Name {
+					markers = append(markers, "current")
+				}
+				if user.IsAdmin {
+					markers = append(markers, "admin")
+				}
+
+				if len(markers) > 0 {
+					fmt.Fprintf(w, " - %s (%s)\n", user.Name, strings.Join(markers, ", "))
+				} else {
+					fmt.Fprintf(w, " - %s\n", user.Name)
+				}
+			}
+		},
+	})
+}
+
+// confirmFlag must be given to commands that delete data for good 
+const confirmFlag = "yes-i-am-sure"
+
+// ConfirmFlag defines the flag that confirms a command deletes data for 
+// good on fs, for the Flags of a CommandInfo 
+func ConfirmFlag(fs *flag.FlagSet) {
+	fs.Bool(confirmFlag, false, "confirm that data is deleted for good")
+}
+
+// HandlerUser manages single users: 
+// - delete <name>: delete a user with their feeds and follows, 
+//   requires --yes-i-am-sure 
+// - rename <old> <new>: change the name of a user 
+// - promote <name>: make a user an admin 
+// - demote <name>: take the admin role from a user 
+// 
+// users can delete and rename themselves; anything else requires 
+// the current user to be an admin. the last admin can't be deleted 
+// or demoted. 
+// 
+// returns an error if: 
+// - the subcommand is unknown or its arguments are wrong (ErrInvalidArgs) 
+// - the current user may not change the user (ErrNotAllowed) 
+// - the user doesn't exist (ErrUserNotFound) 
+// - the new name is taken (ErrAlreadyExists) 
+// - a query or updating the config fails 
+func HandlerUser(s *types.State, cmd Command, user database.User) error {
+	usage := "Usage: go run . user delete [--yes-i-am-sure] <name>|rename <old> <new>|promote <name>|demote <name>"
+
+	subcommand, name := cmd.Args[0], cmd.Args[1]
+	wantArgs := 2
+	if subcommand == "rename" {
+		wantArgs = 3
+	}
+	if len(cmd.Args) != wantArgs {
+		fmt.Println(usage)
+		return fmt.Errorf("%w: wrong number of arguments for user %v", ErrInvalidArgs, subcommand)
+	}
+
+	// users manage themselves, admins manage everyone 
+	self := name == user.Name
+	switch subcommand {
+	case "delete", "rename":
+		if !self && !user.IsAdmin {
+			return fmt.Errorf("%w: only admins can %v other users", ErrNotAllowed, subcommand)
 		}
+	case "promote", "demote":
+		if !user.IsAdmin {
+			return fmt.Errorf("%w: only admins can %v users", ErrNotAllowed, subcommand)
+		}
+	default:
+		fmt.Println(usage)
+		return fmt.Errorf("%w: unknown user subcommand %v", ErrInvalidArgs, subcommand)
 	}
 
-	return nil
+	ctx := context.Background()
+	queries := s.Db
+
+	target, err := queries.GetUser(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %v", ErrUserNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting user %v: %w", name, err)
+	}
+
+	// keep at least one admin around to run reset and manage users 
+	if target.IsAdmin && (subcommand == "delete" || subcommand == "demote") {
+		lastAdmin, err := isLastAdmin(s, target)
+		if err != nil {
+			return err
+		}
+		if lastAdmin {
+			return fmt.Errorf("%w: %v is the last admin, promote another user first", ErrNotAllowed, name)
+		}
+	}
+
+	result := userChange{Name: name, Action: subcommand}
+	switch subcommand {
+	case "delete":
+		if !cmd.Bool(confirmFlag) {
+			return fmt.Errorf("%w: deleting %v also deletes their feeds and follows, run it again with --%v", ErrInvalidArgs, name, confirmFlag)
+		}
+		if _, err := queries.DeleteUser(ctx, name); err != nil {
+			return fmt.Errorf("error deleting user %v: %w", name, err)
+		}
+	case "rename":
+		newName := cmd.Args[2]
+		_, err := queries.RenameUser(ctx, database.RenameUserParams{
+			NewName:   newName,
+			UpdatedAt: time.Now(),
+			OldName:   name,
+		})
+		if store.IsDuplicate(err) {
+			return fmt.Errorf("user %v %w", newName, ErrAlreadyExists)
+		}
+		if err != nil {
+			return fmt.Errorf("error renaming user %v: %w", name, err)
+		}
+
+		// stay logged in under the new name 
+		if s.Config.Current_user_name == name {
+			if err := s.Config.SetUser(newName); err != nil {
+				return fmt.Errorf("error setting user %v: %w", newName, err)
+			}
+		}
+		result.NewName = newName
+	case "promote", "demote":
+		_, err := queries.SetUserAdmin(ctx, database.SetUserAdminParams{
+			Name:      name,
+			IsAdmin:   subcommand == "promote",
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error updating user %v: %w", name, err)
+		}
+	}
+
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[userChange]{
+		Columns: []output.Column[userChange]{
+			{Key: "user", Value: func(result userChange) any { return result.Name }},
+			{Key: "action", Value: func(result userChange) any { return result.Action }},
+			{Key: "new_name", Value: func(result userChange) any { return result.NewName }},
+		},
+		Text: func(w io.Writer, results []userChange) {
+			switch result.Action {
+			case "rename":
+				fmt.Fprintf(w, "user %v was renamed to %v\n", name, result.NewName)
+			case "delete":
+				fmt.Fprintf(w, "user %v was deleted\n", name)
+			default:
+				fmt.Fprintf(w, "user %v was %vd\n", name, result.Action)
+			}
+		},
+	})
+}
+
+// userChange is what the user command did to a user. NewName is only 
+// set by rename 
+type userChange struct {
+	Name    string
+	Action  string
+	NewName string
+}
+
+// isLastAdmin reports whether user is the only admin 
+// 
+// returns an error if listing the users fails 
+func isLastAdmin(s *types.State, user database.User) (bool, error) {
+	users, err := s.Db.GetUsers(context.Background())
+	if err != nil {
+		return false, fmt.Errorf("error getting users from database: %w", err)
+	}
+
+	for _, other := range users {
+		if other.IsAdmin && other.ID != user.ID {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // HandlerAgg creates a ticker with the time provided 
 // to run a loop using scrapeFeeds, always getting the next 
 // feed to fetch 
-// returns an error if parsing time provided fails 
+// it also LISTENs for refresh notifications sent by addfeed, follow 
+// and refresh, fetching the notified feed immediately 
+// returns an error if the time provided isn't a positive duration 
+// (ErrInvalidArgs) or listening fails 
 func HandlerAgg(s *types.State, cmd Command) error {
 	timeBetweenReqs, err := time.ParseDuration(cmd.Args[0])
-	fmt.Println("Time between reqs: ", timeBetweenReqs)
 	if err != nil {
-		return fmt.Errorf("error parsing time: %w", err)
+		return fmt.Errorf("%w: error parsing time: %w", ErrInvalidArgs, err)
+	}
+	if timeBetweenReqs <= 0 {
+		return fmt.Errorf("%w: time between requests must be positive, got %v", ErrInvalidArgs, timeBetweenReqs)
+	}
+	fmt.Println("Time between reqs: ", timeBetweenReqs)
+
+	// refresh notifications only exist on Postgres; on other backends 
+	// the channel stays nil and never delivers 
+	var notifications <-chan *pq.Notification
+	if s.Backend == store.BackendPostgres {
+		listener, err := listenFeedRefresh(s.Config.Db_url)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+		notifications = listener.Notify
+
+		// check the listener connection is still alive 
+		go func() {
+			for range time.Tick(90 * time.Second) {
+				listener.Ping()
+			}
+		}()
 	}
 
 	ticker := time.NewTicker(timeBetweenReqs)
-	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+	defer ticker.Stop()
+
+	scrapeFeeds(s)
+	for {
+		select {
+		case <-ticker.C:
+			scrapeFeeds(s)
+		case notification := <-notifications:
+			// a nil notification is sent after the listener reconnects 
+			if notification == nil {
+				continue
+			}
+			if err := refreshFeed(s, notification.Extra); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
 	}
 }
 
@@ -158,14 +458,10 @@ func HandlerAgg(s *types.State, cmd Command) error {
 // it integrates the user to the created feed 
 // 
 // returns an error if: 
-// - name and url is not provided 
+// - a feed with the url already exists (ErrAlreadyExists) 
 // - the creation of a feed in db fails 
 // - the association between the feed and user fails 
 func HandlerAddFeed(s *types.State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 2 {
-		return fmt.Errorf("not enough arguments provided")
-	}
-
 	ctx := context.Background()
 	name := cmd.Args[0]
 	url := cmd.Args[1]
@@ -178,6 +474,9 @@ func HandlerAddFeed(s *types.State, cmd Command, user database.User) error {
 	CreatedAt: time.Now(),
 	UpdatedAt: time.Now(),
 })
+	if store.IsDuplicate(err) {
+		return fmt.Errorf("feed %v %w", url, ErrAlreadyExists)
+	}
 	if err != nil {
 		return fmt.Errorf("error inserting feed in query CreateFeed: %w", err)
 	}
@@ -192,19 +491,30 @@ func HandlerAddFeed(s *types.State, cmd Command, user database.User) error {
 		return fmt.Errorf("error adding feed in list of following feeds by user %v: %w", user, err)
 	}
 
-	fmt.Println("Feed follows added succesfully")
+	// let a running agg fetch the new feed right away 
+	notifyFeedRefresh(s, insertedFeed.Url)
 
-	fmt.Println("feed recorded succesfully!")
-	fmt.Printf("ID: %v\nName: %v\nUrl: %v\nCreated At: %v\nUpdated At: %v\n", insertedFeed.ID, insertedFeed.Url, insertedFeed.UserID, insertedFeed.CreatedAt, insertedFeed.UpdatedAt)
-
-	return nil 
+	return output.RenderOne(os.Stdout, s.Output, insertedFeed, output.List[database.Feed]{
+		Columns: []output.Column[database.Feed]{
+			{Key: "id", Value: func(feed database.Feed) any { return feed.ID }},
+			{Key: "name", Value: func(feed database.Feed) any { return feed.Name }},
+			{Key: "url", Value: func(feed database.Feed) any { return feed.Url }},
+			{Key: "user", Value: func(feed database.Feed) any { return user.Name }},
+			{Key: "created_at", Value: func(feed database.Feed) any { return feed.CreatedAt }},
+		},
+		Text: func(w io.Writer, feeds []database.Feed) {
+			fmt.Fprintln(w, "Feed follows added succesfully")
+			fmt.Fprintln(w, "feed recorded succesfully!")
+			fmt.Fprintf(w, "ID: %v\nName: %v\nUrl: %v\nCreated At: %v\nUpdated At: %v\n", insertedFeed.ID, insertedFeed.Url, insertedFeed.UserID, insertedFeed.CreatedAt, insertedFeed.UpdatedAt)
+		},
+	})
 }
 
 // HandlerListFeeds fetchs all feeds and prints all the 
 // records one by one showing name, url and the user 
 // that owns the feed 
 // 
-// returns an error if the query GetFeeds fails 
+// returns an error if the query GetFeeds or printing fails 
 func HandlerListFeeds(s *types.State, cmd Command) error {
 	ctx := context.Background()
 	queries := s.Db 
@@ -214,16 +524,23 @@ func HandlerListFeeds(s *types.State, cmd Command) error {
 		return fmt.Errorf("error fetching the list of feeds: %w", err)
 	}
 
-	if len(listFeeds) == 0 {
+	if len(listFeeds) == 0 && s.Output == output.Text {
 		return fmt.Errorf("no feeds in the actual user")
 	}
 
-	for _, feed := range listFeeds {
-		fmt.Println("")
-		fmt.Printf("Name: %v\nURL: %v\nUsername: %v\n", feed.Name, feed.Url, feed.Name_2)
-	}
-
-	return nil
+	return output.Render(os.Stdout, s.Output, listFeeds, output.List[database.GetFeedsRow]{
+		Columns: []output.Column[database.GetFeedsRow]{
+			{Key: "name", Value: func(feed database.GetFeedsRow) any { return feed.Name }},
+			{Key: "url", Value: func(feed database.GetFeedsRow) any { return feed.Url }},
+			{Key: "user", Value: func(feed database.GetFeedsRow) any { return feed.Name_2 }},
+		},
+		Text: func(w io.Writer, feeds []database.GetFeedsRow) {
+			for _, feed := range feeds {
+				fmt.Fprintln(w, "")
+				fmt.Fprintf(w, "Name: %v\nURL: %v\nUsername: %v\n", feed.Name, feed.Url, feed.Name_2)
+			}
+		},
+	})
 }
 
 // HandlerFollow creates a feed_follows relationship between the current user and a feed. 
@@ -231,18 +548,18 @@ func HandlerListFeeds(s *types.State, cmd Command) error {
 // the association in the database. On success, it displays the feed name and username. 
 //
 // returns error if:
-// - feed lookup by URL fails (feed doesn't exist)
+// - feed lookup by URL fails (ErrFeedNotFound if the feed doesn't exist)
 // - user retrieval fails (user not authenticated)
-// - feed follow creation fails (duplicate violation)
+// - feed follow creation fails (ErrAlreadyExists if already followed)
 func HandlerFollow(s *types.State, cmd Command, user database.User) error {
 	ctx := context.Background()
 	url := cmd.Args[0]
 	queries := s.Db 
 	
 	// lookup feed by URL to ensures it exists 
-	feed, err := queries.GetFeedByUrl(ctx, url)
+	feed, err := getFeedByUrl(s, url)
 	if err != nil {
-		return fmt.Errorf("error getting feed by provided url: %w", err)
+		return err
 	}
 
 	// create feed_follows association between user and feed 
@@ -252,18 +569,32 @@ func HandlerFollow(s *types.State, cmd Command, user database.User) error {
 		UserID: user.ID,
 		FeedID: feed.ID,
 	})
+	if store.IsDuplicate(err) {
+		return fmt.Errorf("feed follow of %v by %v %w", url, user.Name, ErrAlreadyExists)
+	}
 	if err != nil {
 		return fmt.Errorf("error creating feed follow: %w", err)
 	}
 
-	fmt.Printf("Feed's name: %v\nCurrent user: %v\n", insertFeedFollow.FeedName, insertFeedFollow.UserName)
+	// let a running agg fetch the followed feed right away 
+	notifyFeedRefresh(s, feed.Url)
 
-	return nil 
+	return output.RenderOne(os.Stdout, s.Output, insertFeedFollow, output.List[database.CreateFeedFollowRow]{
+		Columns: []output.Column[database.CreateFeedFollowRow]{
+			{Key: "feed", Value: func(follow database.CreateFeedFollowRow) any { return follow.FeedName }},
+			{Key: "url", Value: func(follow database.CreateFeedFollowRow) any { return feed.Url }},
+			{Key: "user", Value: func(follow database.CreateFeedFollowRow) any { return follow.UserName }},
+			{Key: "created_at", Value: func(follow database.CreateFeedFollowRow) any { return follow.CreatedAt }},
+		},
+		Text: func(w io.Writer, follows []database.CreateFeedFollowRow) {
+			fmt.Fprintf(w, "Feed's name: %v\nCurrent user: %v\n", insertFeedFollow.FeedName, insertFeedFollow.UserName)
+		},
+	})
 }
 
 // HandlerFollowing fetchs all RSS feeds that the logged user is following 
 // iterate over them and displays all of the RSS feed names
-// it fails if the query to get all the feeds fails 
+// it fails if the query to get all the feeds or printing fails 
 func HandlerFollowing(s *types.State, cmd Command, user database.User) error {
 	ctx := context.Background() 
 	queries := s.Db
@@ -273,32 +604,34 @@ func HandlerFollowing(s *types.State, cmd Command, user database.User) error {
 		return fmt.Errorf("error getting the feed followed by user %v: %w", user.Name, err)
 	}
 
-	fmt.Printf("Current user: %v\n", user.Name)
-	for _, feed := range feedFollows {
-			fmt.Printf("Feed: %v", feed.FeedName)
-		}	
-	
-	return nil 
+	return output.Render(os.Stdout, s.Output, feedFollows, output.List[database.GetFeedFollowsForUserRow]{
+		Columns: []output.Column[database.GetFeedFollowsForUserRow]{
+			{Key: "feed", Value: func(follow database.GetFeedFollowsForUserRow) any { return follow.FeedName }},
+			{Key: "user", Value: func(follow database.GetFeedFollowsForUserRow) any { return follow.UserName }},
+		},
+		Text: func(w io.Writer, feedFollows []database.GetFeedFollowsForUserRow) {
+			fmt.Fprintf(w, "Current user: %v\n", user.Name)
+			for _, feed := range feedFollows {
+				fmt.Fprintf(w, "Feed: %v\n", feed.FeedName)
+			}
+		},
+	})
 }
 
 // HandlerUnfollow unfollow a RSS feed that the logged user is following 
 // based on a provided URL 
 // 
 // returns an error if: 
-// - url is not provided
+// - the feed doesn't exist (ErrFeedNotFound)
 // - queries to get feed by url and delete feed fails 
 func HandlerUnfollow(s *types.State, cmd Command, user database.User) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("url not provided")
-	}
-
 	ctx := context.Background() 
 	url := cmd.Args[0]
 	queries := s.Db 
 
-	feed, err := queries.GetFeedByUrl(ctx, url)
+	feed, err := getFeedByUrl(s, url)
 	if err != nil {
-		return fmt.Errorf("error getting the feed with the provided url: %w", err)
+		return err
 	}
 
 	err = queries.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
@@ -309,17 +642,98 @@ func HandlerUnfollow(s *types.State, cmd Command, user database.User) error {
 		return fmt.Errorf("error deleting feed follows record: %w", err)
 	}
 
-	return nil 
+	// the text format stays quiet, as it always did 
+	return output.RenderOne(os.Stdout, s.Output, feed, output.List[database.Feed]{
+		Columns: []output.Column[database.Feed]{
+			{Key: "feed", Value: func(feed database.Feed) any { return feed.Name }},
+			{Key: "url", Value: func(feed database.Feed) any { return feed.Url }},
+			{Key: "user", Value: func(feed database.Feed) any { return user.Name }},
+		},
+		Text: func(w io.Writer, feeds []database.Feed) {},
+	})
 }
 
-// scrapeFeeds is a helper function that gets the next feed to fetch, 
-// mark the returned feed as fetched, fetch info about the feed 
-// and prints the name, id, url, created at, updated at and last fetched at fields
+// HandlerRefresh asks a running agg process to fetch the feed 
+// with the provided url immediately. stores that can't notify agg 
+// (SQLite) download the feed right away instead, print how many items 
+// it has and mark it as fetched. 
+// 
+// returns an error if: 
+// - the feed doesn't exist (ErrFeedNotFound) 
+// - sending the notification or downloading the feed fails 
+func HandlerRefresh(s *types.State, cmd Command) error {
+	ctx := context.Background() 
+	url := cmd.Args[0]
+	queries := s.Db 
+
+	record, err := getFeedByUrl(s, url)
+	if err != nil {
+		return err
+	}
+
+	result := refreshResult{Feed: record, Status: "requested"}
+
+	err = queries.NotifyFeedRefresh(ctx, record.Url)
+	if errors.Is(err, store.ErrNotSupported) {
+		rssFeed, err := feed.FetchFeed(ctx, record.Url)
+		if err != nil {
+			return fmt.Errorf("error fetching feed %v: %w", record.Url, err)
+		}
+		result.Feed, err = markFeedFetched(s, record)
+		if err != nil {
+			return err
+		}
+		result.Status = "fetched"
+		result.Items = len(rssFeed.Channel.Item)
+	} else if err != nil {
+		return fmt.Errorf("error notifying refresh of feed %v: %w", record.Url, err)
+	}
+
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[refreshResult]{
+		Columns: []output.Column[refreshResult]{
+			{Key: "feed", Value: func(result refreshResult) any { return result.Feed.Name }},
+			{Key: "url", Value: func(result refreshResult) any { return result.Feed.Url }},
+			{Key: "status", Value: func(result refreshResult) any { return result.Status }},
+			{Key: "items", Value: func(result refreshResult) any { return result.Items }},
+		},
+		Text: func(w io.Writer, results []refreshResult) {
+			if result.Status == "fetched" {
+				fmt.Fprintf(w, "fetched feed %v: %d items\n", record.Name, result.Items)
+			} else {
+				fmt.Fprintf(w, "refresh requested for feed %v\n", record.Name)
+			}
+		},
+	})
+}
+
+// refreshResult is the outcome of refresh: "requested" from a running 
+// agg, or "fetched" when the feed was downloaded right away 
+type refreshResult struct {
+	Feed   database.Feed
+	Status string
+	Items  int // items in the downloaded feed
+}
+
+// getFeedByUrl looks up the feed with the given url 
 // 
-// returns an error if all of the above queries fails:
-// - next feed to fetch  
-// - mark feed as fetched 
-// - fetch the feed 
+// returns ErrFeedNotFound if there is no such feed, or an error 
+// if the query fails 
+func getFeedByUrl(s *types.State, url string) (database.Feed, error) {
+	feed, err := s.Db.GetFeedByUrl(context.Background(), url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("%w: %v", ErrFeedNotFound, url)
+	}
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("error getting the feed with url %v: %w", url, err)
+	}
+
+	return feed, nil
+}
+
+// scrapeFeeds is a helper function that gets the next feed to fetch 
+// and scrapes it with scrapeFeed 
+// 
+// returns an error if getting the next feed to fetch or scraping it fails 
 func scrapeFeeds(s *types.State) error {
 	ctx := context.Background() 
 	queries := s.Db 
@@ -330,21 +744,481 @@ func scrapeFeeds(s *types.State) error {
 		return fmt.Errorf("error getting the next feed to scrape: %w", err)
 	}
 
-	err = queries.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+	return scrapeFeed(s, nextFeed)
+}
+
+// refreshFeed scrapes the feed with the given url out of turn, 
+// as requested through a refresh notification 
+// 
+// returns an error if the feed doesn't exist or scraping it fails 
+func refreshFeed(s *types.State, url string) error {
+	feed, err := getFeedByUrl(s, url)
+	if err != nil {
+		return fmt.Errorf("error getting the feed to refresh: %w", err)
+	}
+
+	return scrapeFeed(s, feed)
+}
+
+// scrapeFeed marks the given feed as fetched, fetch info about the feed 
+// and prints the name, id, url, created at, updated at and last fetched at fields
+// 
+// returns an error if marking or fetching the feed fails 
+func scrapeFeed(s *types.State, feed database.Feed) error {
+	fetchFeed, err := markFeedFetched(s, feed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nID: %v\nName: %v\nURL: %v\nCreated At: %v\nUpdated At: %v\nLast Fetched: %v\n", fetchFeed.ID, fetchFeed.Name, fetchFeed.Url, fetchFeed.CreatedAt, fetchFeed.UpdatedAt, fetchFeed.LastFetchedAt)
+
+	return nil 
+}
+
+// markFeedFetched sets the last fetched time of the given feed to now, 
+// moving it to the back of the fetch queue, and returns it as stored 
+// 
+// returns an error if updating or reading back the feed fails 
+func markFeedFetched(s *types.State, feed database.Feed) (database.Feed, error) {
+	ctx := context.Background() 
+	queries := s.Db 
+
+	err := queries.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
 		LastFetchedAt: sql.NullTime{Time: time.Now(), Valid: true},
 		UpdatedAt: time.Now(),
-		ID: nextFeed.ID,
+		ID: feed.ID,
 	})
 	if err != nil {
-		return fmt.Errorf("error marking feed as fetched: %w", err)
+		return database.Feed{}, fmt.Errorf("error marking feed as fetched: %w", err)
 	}
 
-	fetchFeed, err := queries.GetFeedByUrl(ctx, nextFeed.Url)
+	fetchFeed, err := queries.GetFeedByUrl(ctx, feed.Url)
 	if err != nil {
-		return fmt.Errorf("error fetching feed: %w", err)
+		return database.Feed{}, fmt.Errorf("error getting feed: %w", err)
 	}
 
-	fmt.Printf("\nID: %v\nName: %v\nURL: %v\nCreated At: %v\nUpdated At: %v\nLast Fetched: %v\n", fetchFeed.ID, fetchFeed.Name, fetchFeed.Url, fetchFeed.CreatedAt, fetchFeed.UpdatedAt, fetchFeed.LastFetchedAt)
+	return fetchFeed, nil 
+}
 
-	return nil 
+// HandlerBackup writes users, feeds and feed follows to a 
+// versioned, gzip compressed JSON archive at the provided path 
+// 
+// returns an error if: 
+// - reading the database fails 
+// - creating or writing the file fails 
+func HandlerBackup(s *types.State, cmd Command) error {
+	path := cmd.Args[0]
+
+	archive, err := backup.Dump(context.Background(), s.Db)
+	if err != nil {
+		return fmt.Errorf("error reading database for backup: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating backup file: %w", err)
+	}
+
+	if err = backup.Write(file, archive); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("error writing backup file: %w", err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("error closing backup file: %w", err)
+	}
+
+	result := archiveResult{File: path, Archive: archive}
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[archiveResult]{
+		Columns: archiveColumns,
+		Text: func(w io.Writer, results []archiveResult) {
+			fmt.Fprintf(w, "backup written to %v (%d users, %d feeds, %d follows)\n", path, len(archive.Users), len(archive.Feeds), len(archive.FeedFollows))
+		},
+	})
+}
+
+// archiveResult is the archive written by backup or loaded by restore 
+type archiveResult struct {
+	File    string
+	Archive backup.Archive
+}
+
+// archiveColumns describe the archive in the results of backup and restore 
+var archiveColumns = []output.Column[archiveResult]{
+	{Key: "file", Value: func(result archiveResult) any { return result.File }},
+	{Key: "users", Value: func(result archiveResult) any { return len(result.Archive.Users) }},
+	{Key: "feeds", Value: func(result archiveResult) any { return len(result.Archive.Feeds) }},
+	{Key: "follows", Value: func(result archiveResult) any { return len(result.Archive.FeedFollows) }},
+}
+
+// HandlerRestore loads an archive written by backup into the database. 
+// by default the archive is merged into the existing data, which needs 
+// no logged in user, so a backup can be restored after reset. with 
+// --replace all existing users, feeds and follows are deleted first, 
+// which like reset needs an admin (if there are users) and --yes-i-am-sure. 
+// the restore runs in a single transaction, so a failure changes nothing. 
+// 
+// returns an error if: 
+// - --replace is given without --yes-i-am-sure (ErrInvalidArgs) or by a 
+//   user who is not an admin (ErrNotLoggedIn, ErrNotAllowed) 
+// - the file can't be read or is not a supported archive 
+// - restoring any record fails 
+func HandlerRestore(s *types.State, cmd Command) error {
+	path := cmd.Args[0]
+	replace := cmd.Bool("replace")
+
+	if replace {
+		if !cmd.Bool(confirmFlag) {
+			return fmt.Errorf("%w: restore --replace deletes every user, feed and follow first, run it again with --%v", ErrInvalidArgs, confirmFlag)
+		}
+		if err := requireAdmin(s, "replace the database"); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	defer file.Close()
+
+	archive, err := backup.Read(file)
+	if err != nil {
+		return fmt.Errorf("error reading backup file: %w", err)
+	}
+
+	err = backup.Restore(context.Background(), s.Db, archive, replace)
+	if err != nil {
+		return fmt.Errorf("error restoring backup: %w", err)
+	}
+
+	result := archiveResult{File: path, Archive: archive}
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[archiveResult]{
+		Columns: archiveColumns,
+		Text: func(w io.Writer, results []archiveResult) {
+			fmt.Fprintf(w, "restored %d users, %d feeds and %d follows from %v\n", len(archive.Users), len(archive.Feeds), len(archive.FeedFollows), path)
+		},
+	})
+}
+
+// requireAdmin makes sure the current user is an admin before a command 
+// deletes the data of every user. a database without users has nothing 
+// to protect, so the command goes on without a logged in user. 
+// 
+// returns an error if the current user doesn't exist (ErrNotLoggedIn), 
+// isn't an admin (ErrNotAllowed) or the users can't be read 
+func requireAdmin(s *types.State, action string) error {
+	users, err := s.Db.GetUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	name := s.Config.Current_user_name
+	i := slices.IndexFunc(users, func(user database.User) bool {
+		return user.Name == name
+	})
+	if i < 0 {
+		return fmt.Errorf("%w: user %v does not exist, run login or register first", ErrNotLoggedIn, name)
+	}
+	if !users[i].IsAdmin {
+		return fmt.Errorf("%w: only admins can %v", ErrNotAllowed, action)
+	}
+
+	return nil
+}
+
+// HandlerMigrate manages the database schema with the migrations 
+// embedded in the binary: 
+// - up: apply every pending migration 
+// - down: roll back the last applied migration 
+// - status: list migrations and whether they are applied 
+// - to <version>: migrate up or down to the given version 
+// rolling back may drop tables with their data, so down and to a lower 
+// version only run with --yes-i-am-sure and, like reset, for an admin. 
+// 
+// returns an error if the subcommand is unknown, the version is 
+// missing or not a number, a roll back isn't confirmed or allowed 
+// (ErrInvalidArgs, ErrNotLoggedIn, ErrNotAllowed) or any migration fails 
+func HandlerMigrate(s *types.State, cmd Command) error {
+	ctx := context.Background()
+
+	switch cmd.Args[0] {
+	case "up":
+		applied, err := migrate.Up(ctx, s.Conn, s.Backend)
+		if err != nil {
+			return err
+		}
+		return renderMigrations(s, applied, "up", func(w io.Writer) {
+			if len(applied) == 0 {
+				fmt.Fprintln(w, "schema is already up to date")
+			}
+			for _, file := range applied {
+				fmt.Fprintf(w, "applied %v\n", file)
+			}
+		})
+	case "down":
+		current, err := migrate.Version(ctx, s.Conn, s.Backend)
+		if err != nil {
+			return err
+		}
+		if err := confirmRollback(s, cmd, current); err != nil {
+			return err
+		}
+		rolledBack, err := migrate.Down(ctx, s.Conn, s.Backend)
+		if err != nil {
+			return err
+		}
+		return renderMigrations(s, []string{rolledBack}, "down", func(w io.Writer) {
+			fmt.Fprintf(w, "rolled back %v\n", rolledBack)
+		})
+	case "status":
+		statuses, err := migrate.List(ctx, s.Conn, s.Backend)
+		if err != nil {
+			return err
+		}
+		return output.Render(os.Stdout, s.Output, statuses, output.List[migrate.Status]{
+			Columns: []output.Column[migrate.Status]{
+				{Key: "version", Value: func(status migrate.Status) any { return status.Version }},
+				{Key: "file", Value: func(status migrate.Status) any { return status.File }},
+				{Key: "applied", Value: func(status migrate.Status) any { return status.Applied }},
+				{Key: "applied_at", Value: func(status migrate.Status) any { return status.AppliedAt }},
+			},
+			Text: func(w io.Writer, statuses []migrate.Status) {
+				for _, status := range statuses {
+					if status.Applied {
+						fmt.Fprintf(w, " - %v applied at %v\n", status.File, status.AppliedAt)
+					} else {
+						fmt.Fprintf(w, " - %v pending\n", status.File)
+					}
+				}
+			},
+		})
+	case "to":
+		if len(cmd.Args) < 2 {
+			fmt.Println("Usage: go run . migrate to <version>")
+			return fmt.Errorf("%w: version not provided", ErrInvalidArgs)
+		}
+		version, err := strconv.ParseInt(cmd.Args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid version %v: %w", ErrInvalidArgs, cmd.Args[1], err)
+		}
+		current, err := migrate.Version(ctx, s.Conn, s.Backend)
+		if err != nil {
+			return err
+		}
+		if version < current {
+			if err := confirmRollback(s, cmd, current); err != nil {
+				return err
+			}
+		}
+		migrated, err := migrate.To(ctx, s.Conn, s.Backend, version)
+		if err != nil {
+			return err
+		}
+		direction := "up"
+		if version < current {
+			direction = "down"
+		}
+		return renderMigrations(s, migrated, direction, func(w io.Writer) {
+			for _, file := range migrated {
+				fmt.Fprintf(w, "migrated %v\n", file)
+			}
+			fmt.Fprintf(w, "schema is at version %d\n", version)
+		})
+	default:
+		return fmt.Errorf("%w: unknown migrate subcommand %v", ErrInvalidArgs, cmd.Args[0])
+	}
+}
+
+// confirmRollback checks a migration that rolls the schema back from 
+// version current: it needs --yes-i-am-sure and, once users have roles, 
+// an admin. below migrate.RolesVersion there is no admin to ask for, and 
+// rolling back that far from a newer schema already took one. 
+// 
+// returns an error if the flag is missing (ErrInvalidArgs) or the current 
+// user isn't an admin (ErrNotLoggedIn, ErrNotAllowed) 
+func confirmRollback(s *types.State, cmd Command, current int64) error {
+	if !cmd.Bool(confirmFlag) {
+		return fmt.Errorf("%w: rolling back migrations may drop tables and their data, run it again with --%v", ErrInvalidArgs, confirmFlag)
+	}
+	if current < migrate.RolesVersion {
+		return nil
+	}
+	return requireAdmin(s, "roll back the schema")
+}
+
+// renderMigrations prints the migration files that ran in the given 
+// direction, through text in the text format 
+func renderMigrations(s *types.State, files []string, direction string, text func(w io.Writer)) error {
+	return output.Render(os.Stdout, s.Output, files, output.List[string]{
+		Columns: []output.Column[string]{
+			{Key: "file", Value: func(file string) any { return file }},
+			{Key: "direction", Value: func(file string) any { return direction }},
+		},
+		Text: func(w io.Writer, files []string) {
+			text(w)
+		},
+	})
+}
+
+// HandlerConfig inspects the effective configuration. 
+// `config show` prints every value, and `config show --origin` also 
+// prints the layer it came from (default, file, env or flag). 
+// 
+// returns an error if the subcommand is unknown 
+func HandlerConfig(s *types.State, cmd Command) error {
+	if cmd.Args[0] != "show" {
+		fmt.Println("Usage: go run . config show [--origin]")
+		return fmt.Errorf("%w: unknown config subcommand %v", ErrInvalidArgs, cmd.Args[0])
+	}
+
+	showOrigin := cmd.Bool("origin")
+
+	columns := []output.Column[config.Setting]{
+		{Key: "key", Value: func(setting config.Setting) any { return setting.Key }},
+		{Key: "value", Value: func(setting config.Setting) any { return setting.Value }},
+	}
+	if showOrigin {
+		columns = append(columns, output.Column[config.Setting]{
+			Key: "origin", Value: func(setting config.Setting) any { return setting.Origin },
+		})
+	}
+
+	return output.Render(os.Stdout, s.Output, s.Config.Settings(), output.List[config.Setting]{
+		Columns: columns,
+		Text: func(w io.Writer, settings []config.Setting) {
+			for _, setting := range settings {
+				if showOrigin {
+					fmt.Fprintf(w, "%v = %v (%v)\n", setting.Key, setting.Value, setting.Origin)
+				} else {
+					fmt.Fprintf(w, "%v = %v\n", setting.Key, setting.Value)
+				}
+			}
+		},
+	})
+}
+
+// HandlerSession manages the session files written when GATOR_SESSION 
+// is set: `session clear` removes the file of the current session, e.g. 
+// from `trap 'gator session clear' EXIT`, and `session clear --all` 
+// removes every session file. 
+// 
+// returns an error if the subcommand is unknown, no session is in use 
+// without --all or a file can't be removed 
+func HandlerSession(s *types.State, cmd Command) error {
+	if cmd.Args[0] != "clear" {
+		fmt.Println("Usage: go run . session clear [--all]")
+		return fmt.Errorf("%w: unknown session subcommand %v", ErrInvalidArgs, cmd.Args[0])
+	}
+
+	removed, err := config.ClearSessions(cmd.Bool("all"))
+	if err != nil {
+		return err
+	}
+
+	result := sessionResult{Files: removed}
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[sessionResult]{
+		Columns: []output.Column[sessionResult]{
+			{Key: "removed", Value: func(result sessionResult) any { return len(result.Files) }},
+			{Key: "files", Value: func(result sessionResult) any { return result.Files }},
+		},
+		Text: func(w io.Writer, results []sessionResult) {
+			fmt.Fprintf(w, "removed %d session files\n", len(removed))
+		},
+	})
+}
+
+// sessionResult lists the session files removed by session clear 
+type sessionResult struct {
+	Files []string
+}
+
+// HandlerProfile manages named configuration profiles, each with 
+// its own database url and current user: 
+// - list: show every profile, marking the one in use 
+// - use <name>: make name the current profile 
+// - add <name> <db_url> [user]: define a new profile 
+// - remove <name>: delete a profile that is not in use 
+// 
+// returns an error if the subcommand is unknown, its arguments are 
+// missing, or the config file can't be updated 
+func HandlerProfile(s *types.State, cmd Command) error {
+	usage := "Usage: go run . profile list|use <name>|add <name> <db_url> [user]|remove <name>"
+
+	switch cmd.Args[0] {
+	case "list":
+		return output.Render(os.Stdout, s.Output, s.Config.ProfileNames(), output.List[string]{
+			Columns: []output.Column[string]{
+				{Key: "name", Value: func(name string) any { return name }},
+				{Key: "current", Value: func(name string) any { return name == s.Config.Profile }},
+			},
+			Text: func(w io.Writer, names []string) {
+				for _, name := range names {
+					if name == s.Config.Profile {
+						fmt.Fprintf(w, " - %s (current)\n", name)
+					} else {
+						fmt.Fprintf(w, " - %s\n", name)
+					}
+				}
+			},
+		})
+	case "use":
+		if len(cmd.Args) < 2 {
+			fmt.Println(usage)
+			return fmt.Errorf("%w: profile name not provided", ErrInvalidArgs)
+		}
+		if err := s.Config.UseProfile(cmd.Args[1]); err != nil {
+			return fmt.Errorf("error switching profile: %w", err)
+		}
+		return renderProfileChange(s, cmd.Args[1], "use", "profile %v is now in use\n")
+	case "add":
+		if len(cmd.Args) < 3 {
+			fmt.Println(usage)
+			return fmt.Errorf("%w: profile name or db_url not provided", ErrInvalidArgs)
+		}
+		user := ""
+		if len(cmd.Args) > 3 {
+			user = cmd.Args[3]
+		}
+		if err := s.Config.AddProfile(cmd.Args[1], cmd.Args[2], user); err != nil {
+			return fmt.Errorf("error adding profile: %w", err)
+		}
+		return renderProfileChange(s, cmd.Args[1], "add", "profile %v added\n")
+	case "remove":
+		if len(cmd.Args) < 2 {
+			fmt.Println(usage)
+			return fmt.Errorf("%w: profile name not provided", ErrInvalidArgs)
+		}
+		if err := s.Config.RemoveProfile(cmd.Args[1]); err != nil {
+			return fmt.Errorf("error removing profile: %w", err)
+		}
+		return renderProfileChange(s, cmd.Args[1], "remove", "profile %v removed\n")
+	default:
+		return fmt.Errorf("%w: unknown profile subcommand %v", ErrInvalidArgs, cmd.Args[0])
+	}
+}
+
+// profileChange is what the profile command did to a profile 
+type profileChange struct {
+	Name   string
+	Action string
+}
+
+// renderProfileChange prints the change made by profile use, add or 
+// remove, formatting the profile name with text in the text format 
+func renderProfileChange(s *types.State, name, action, text string) error {
+	result := profileChange{Name: name, Action: action}
+	return output.RenderOne(os.Stdout, s.Output, result, output.List[profileChange]{
+		Columns: []output.Column[profileChange]{
+			{Key: "profile", Value: func(result profileChange) any { return result.Name }},
+			{Key: "action", Value: func(result profileChange) any { return result.Action }},
+		},
+		Text: func(w io.Writer, results []profileChange) {
+			fmt.Fprintf(w, text, result.Name)
+		},
+	})
 }
@@ -0,0 +1,437 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luis-octavius/blog-aggregator/internal/config"
+	"github.com/luis-octavius/blog-aggregator/internal/migrate"
+	"github.com/luis-octavius/blog-aggregator/internal/output"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+	"github.com/luis-octavius/blog-aggregator/internal/types"
+)
+
+// newTestState returns a state over an empty in-memory store, with the
+// configuration read from a temporary home directory
+func newTestState(t *testing.T) *types.State {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	for _, key := range []string{"GATOR_CONFIG", "GATOR_PROFILE", "GATOR_DB_URL", "GATOR_USER", "GATOR_SESSION"} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := config.Read(config.Overrides{})
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+
+	return &types.State{
+		Db:     store.NewMemory(),
+		Config: &cfg,
+		Output: output.Text,
+	}
+}
+
+// run calls a handler with the given arguments
+func run(s *types.State, handler func(*types.State, Command) error, name string, args ...string) error {
+	return handler(s, Command{Name: name, Args: args})
+}
+
+// addFeed adds a feed owned by the current user, failing the test on error
+func addFeed(t *testing.T, s *types.State, name, url string) {
+	t.Helper()
+	if err := run(s, MiddlewareLoggedIn(HandlerAddFeed), "addfeed", name, url); err != nil {
+		t.Fatalf("adding feed %v: %v", url, err)
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestState(t)
+
+	if err := run(s, HandlerRegister, "register", "alice"); err != nil {
+		t.Fatalf("register alice: %v", err)
+	}
+	if err := run(s, HandlerRegister, "register", "bob"); err != nil {
+		t.Fatalf("register bob: %v", err)
+	}
+	if s.Config.Current_user_name != "bob" {
+		t.Errorf("current user after register = %v, want bob", s.Config.Current_user_name)
+	}
+
+	err := run(s, HandlerRegister, "register", "alice")
+	if !errors.Is(err, ErrAlreadyExists) || ExitCode(err) != ExitAlreadyExists {
+		t.Errorf("registering alice twice: got %v, want ErrAlreadyExists", err)
+	}
+
+	if err := run(s, HandlerLogin, "login", "alice"); err != nil {
+		t.Fatalf("login alice: %v", err)
+	}
+	if s.Config.Current_user_name != "alice" {
+		t.Errorf("current user after login = %v, want alice", s.Config.Current_user_name)
+	}
+
+	err = run(s, HandlerLogin, "login", "carol")
+	if !errors.Is(err, ErrUserNotFound) || ExitCode(err) != ExitNotFound {
+		t.Errorf("login as a missing user: got %v, want ErrUserNotFound", err)
+	}
+	if s.Config.Current_user_name != "alice" {
+		t.Errorf("a failed login changed the current user to %v", s.Config.Current_user_name)
+	}
+}
+
+func TestLoginRequired(t *testing.T) {
+	s := newTestState(t)
+
+	err := run(s, MiddlewareLoggedIn(HandlerFollow), "follow", "https://example.com/rss")
+	if !errors.Is(err, ErrNotLoggedIn) || ExitCode(err) != ExitNotLoggedIn {
+		t.Errorf("follow without a user: got %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestFollowAndUnfollow(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+	url := "https://example.com/rss"
+
+	if err := run(s, HandlerRegister, "register", "alice"); err != nil {
+		t.Fatalf("register alice: %v", err)
+	}
+	addFeed(t, s, "example", url)
+	if err := run(s, HandlerRegister, "register", "bob"); err != nil {
+		t.Fatalf("register bob: %v", err)
+	}
+
+	follow := MiddlewareLoggedIn(HandlerFollow)
+	unfollow := MiddlewareLoggedIn(HandlerUnfollow)
+
+	if err := run(s, follow, "follow", url); err != nil {
+		t.Fatalf("bob following %v: %v", url, err)
+	}
+	err := run(s, follow, "follow", url)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("following twice: got %v, want ErrAlreadyExists", err)
+	}
+	err = run(s, follow, "follow", "https://missing.example/rss")
+	if !errors.Is(err, ErrFeedNotFound) || ExitCode(err) != ExitNotFound {
+		t.Errorf("following a missing feed: got %v, want ErrFeedNotFound", err)
+	}
+
+	follows, _ := s.Db.GetFeedFollowsForUser(ctx, "bob")
+	if len(follows) != 1 || follows[0].FeedName != "example" {
+		t.Errorf("bob's follows = %v, want example", follows)
+	}
+
+	if err := run(s, unfollow, "unfollow", url); err != nil {
+		t.Fatalf("bob unfollowing %v: %v", url, err)
+	}
+	if follows, _ := s.Db.GetFeedFollowsForUser(ctx, "bob"); len(follows) != 0 {
+		t.Errorf("bob's follows after unfollow = %v, want none", follows)
+	}
+	if follows, _ := s.Db.GetFeedFollowsForUser(ctx, "alice"); len(follows) != 1 {
+		t.Errorf("alice's follows after bob unfollowed = %v, want one", follows)
+	}
+
+	err = run(s, unfollow, "unfollow", "https://missing.example/rss")
+	if !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("unfollowing a missing feed: got %v, want ErrFeedNotFound", err)
+	}
+}
+
+func TestAggRejectsNonPositiveInterval(t *testing.T) {
+	s := newTestState(t)
+
+	for _, interval := range []string{"0s", "-1m", "soon"} {
+		err := run(s, HandlerAgg, "agg", interval)
+		if !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("agg %v: got %v, want ErrInvalidArgs", interval, err)
+		}
+	}
+}
+
+func TestRefreshWithoutNotifications(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<rss><channel><title>example</title><item><title>one</title></item><item><title>two</title></item></channel></rss>`)
+	}))
+	defer server.Close()
+	url := server.URL + "/rss"
+
+	if err := run(s, HandlerRegister, "register", "alice"); err != nil {
+		t.Fatalf("register alice: %v", err)
+	}
+	addFeed(t, s, "example", url)
+
+	// the in-memory store can't notify agg, so the feed is fetched now
+	if err := run(s, HandlerRefresh, "refresh", url); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	feed, _ := s.Db.GetFeedByUrl(ctx, url)
+	if !feed.LastFetchedAt.Valid {
+		t.Error("refresh didn't mark the feed as fetched")
+	}
+
+	// a feed that can't be downloaded isn't marked as fetched
+	server.Close()
+	if err := run(s, HandlerRefresh, "refresh", url); err == nil {
+		t.Error("refresh of an unreachable feed succeeded")
+	}
+}
+
+func TestUserAdminGuards(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+	user := MiddlewareLoggedIn(HandlerUser)
+
+	for _, name := range []string{"alice", "bob"} {
+		if err := run(s, HandlerRegister, "register", name); err != nil {
+			t.Fatalf("register %v: %v", name, err)
+		}
+	}
+
+	// bob isn't an admin
+	err := run(s, user, "user", "promote", "bob")
+	if !errors.Is(err, ErrNotAllowed) || ExitCode(err) != ExitNotAllowed {
+		t.Errorf("bob promoting bob: got %v, want ErrNotAllowed", err)
+	}
+
+	if err := run(s, HandlerLogin, "login", "alice"); err != nil {
+		t.Fatalf("login alice: %v", err)
+	}
+	err = run(s, user, "user", "demote", "alice")
+	if !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("demoting the last admin: got %v, want ErrNotAllowed", err)
+	}
+
+	if err := run(s, user, "user", "promote", "bob"); err != nil {
+		t.Fatalf("alice promoting bob: %v", err)
+	}
+	if bob, _ := s.Db.GetUser(ctx, "bob"); !bob.IsAdmin {
+		t.Error("bob isn't an admin after promote")
+	}
+}
+
+func TestRestoreGuards(t *testing.T) {
+	s := newTestState(t)
+	path := filepath.Join(t.TempDir(), "gator.backup")
+	restore := func(args ...string) error {
+		cmd := Command{Name: "restore", Args: args}
+		info := CommandInfo{
+			MinArgs: 1, MaxArgs: 1,
+			Flags: func(fs *flag.FlagSet) {
+				fs.Bool("replace", false, "")
+				ConfirmFlag(fs)
+			},
+		}
+		if err := info.parse(&cmd); err != nil {
+			return err
+		}
+		return HandlerRestore(s, cmd)
+	}
+
+	for _, name := range []string{"alice", "bob"} {
+		if err := run(s, HandlerRegister, "register", name); err != nil {
+			t.Fatalf("register %v: %v", name, err)
+		}
+	}
+	if err := run(s, HandlerBackup, "backup", path); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	// bob isn't an admin
+	err := restore("--replace", path)
+	if !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("restore --replace without confirming: got %v, want ErrInvalidArgs", err)
+	}
+	err = restore("--replace", "--"+confirmFlag, path)
+	if !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("restore --replace by bob: got %v, want ErrNotAllowed", err)
+	}
+
+	// a backup can be merged back after reset, without a user
+	if err := s.Db.DeleteUsers(context.Background()); err != nil {
+		t.Fatalf("deleting users: %v", err)
+	}
+	if err := restore(path); err != nil {
+		t.Fatalf("restore after reset: %v", err)
+	}
+	if users, _ := s.Db.GetUsers(context.Background()); len(users) != 2 {
+		t.Errorf("users after restore = %v, want alice and bob", users)
+	}
+}
+
+func TestConfirmRollback(t *testing.T) {
+	s := newTestState(t)
+	parse := func(args ...string) Command {
+		cmd := Command{Name: "migrate", Args: args}
+		if err := (CommandInfo{MinArgs: 1, MaxArgs: 1, Flags: ConfirmFlag}).parse(&cmd); err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		return cmd
+	}
+	confirmed, unconfirmed := parse("down", "--"+confirmFlag), parse("down")
+
+	if err := confirmRollback(s, unconfirmed, migrate.RolesVersion); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("rolling back without confirming: got %v, want ErrInvalidArgs", err)
+	}
+
+	for _, name := range []string{"alice", "bob"} {
+		if err := run(s, HandlerRegister, "register", name); err != nil {
+			t.Fatalf("register %v: %v", name, err)
+		}
+	}
+	if err := confirmRollback(s, confirmed, migrate.RolesVersion); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("bob rolling back: got %v, want ErrNotAllowed", err)
+	}
+	// before roles there is no admin to ask for
+	if err := confirmRollback(s, confirmed, migrate.RolesVersion-1); err != nil {
+		t.Errorf("rolling back from before roles: %v", err)
+	}
+
+	if err := run(s, HandlerLogin, "login", "alice"); err != nil {
+		t.Fatalf("login alice: %v", err)
+	}
+	if err := confirmRollback(s, confirmed, migrate.RolesVersion); err != nil {
+		t.Errorf("alice rolling back: %v", err)
+	}
+}
+
+func TestEmptyListsOnlyFailInText(t *testing.T) {
+	s := newTestState(t)
+
+	if err := run(s, HandlerUsers, "users"); err == nil {
+		t.Error("users on an empty database succeeded in text")
+	}
+
+	s.Output = output.JSON
+	if err := run(s, HandlerUsers, "users"); err != nil {
+		t.Errorf("users on an empty database in json: %v", err)
+	}
+	if err := run(s, HandlerListFeeds, "feeds"); err != nil {
+		t.Errorf("feeds on an empty database in json: %v", err)
+	}
+}
+
+func TestRunRejectsUnsupportedOutput(t *testing.T) {
+	s := newTestState(t)
+	s.Output = output.JSON
+
+	c := Commands{}
+	called := false
+	handler := func(s *types.State, cmd Command) error {
+		called = true
+		return nil
+	}
+	c.Register("text", CommandInfo{}, handler)
+	c.Register("structured", CommandInfo{Output: true}, handler)
+
+	err := c.Run(s, Command{Name: "text"})
+	if !errors.Is(err, ErrInvalidArgs) || called {
+		t.Errorf("running a text only command with json: got %v, called %v", err, called)
+	}
+	if err := c.Run(s, Command{Name: "structured"}); err != nil || !called {
+		t.Errorf("running a command with json output: got %v, called %v", err, called)
+	}
+}
+
+func TestChangesRenderJSON(t *testing.T) {
+	s := newTestState(t)
+	url := "https://example.com/rss"
+
+	for _, name := range []string{"bob", "alice"} {
+		if err := run(s, HandlerRegister, "register", name); err != nil {
+			t.Fatalf("register %v: %v", name, err)
+		}
+	}
+	if err := run(s, HandlerLogin, "login", "bob"); err != nil {
+		t.Fatalf("login bob: %v", err)
+	}
+	addFeed(t, s, "example", url)
+	s.Output = output.JSON
+
+	tests := []struct {
+		name    string
+		handler func(*types.State, Command) error
+		args    []string
+		want    map[string]any
+	}{
+		{
+			name:    "unfollow",
+			handler: MiddlewareLoggedIn(HandlerUnfollow),
+			args:    []string{url},
+			want:    map[string]any{"feed": "example", "url": url, "user": "bob"},
+		},
+		{
+			name:    "user",
+			handler: MiddlewareLoggedIn(HandlerUser),
+			args:    []string{"rename", "alice", "carol"},
+			want:    map[string]any{"user": "alice", "action": "rename", "new_name": "carol"},
+		},
+		{
+			name:    "reset",
+			handler: MiddlewareLoggedIn(HandlerDelete),
+			args:    []string{"--" + confirmFlag},
+			want:    map[string]any{"users": 2.0, "feeds": 1.0, "follows": 0.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// parse the confirmation flag like Run does
+			cmd := Command{Name: tt.name, Args: tt.args}
+			if err := (CommandInfo{MaxArgs: -1, Flags: ConfirmFlag}).parse(&cmd); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			var err error
+			out := captureStdout(t, func() { err = tt.handler(s, cmd) })
+			if err != nil {
+				t.Fatalf("%v: %v", tt.name, err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("%v printed invalid json: %v\n%s", tt.name, err, out)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%v %v = %v, want %v", tt.name, key, got[key], want)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package cli
 
 import (
 	"context"	
+	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/luis-octavius/blog-aggregator/internal/database"
 	"github.com/luis-octavius/blog-aggregator/internal/types"
@@ -15,15 +18,22 @@ import (
 // - following 
 // - addfeed 
 // 
-// returns a new handler function with user authentication pre-validated
+// the user is the resolved current user: --user or GATOR_USER first, then
+// the session named by GATOR_SESSION, and the global config file last
+// 
+// returns a new handler function with user authentication pre-validated,
+// which fails with ErrNotLoggedIn if the current user doesn't exist
 func MiddlewareLoggedIn(handler func(s *types.State, cmd Command, user database.User) error) func(*types.State, Command) error {	
 	return func(s *types.State, cmd Command) error {
 		username := s.Config.Current_user_name
 
 		// fetch user from database to validate authentication 
 		fetchedUser, err := s.Db.GetUser(context.Background(), username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user %v does not exist, run login or register first", ErrNotLoggedIn, username)
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf("error getting the current user %v: %w", username, err)
 		}
 		
 		// execute the original handler with authenticated user
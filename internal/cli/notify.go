@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+	"github.com/luis-octavius/blog-aggregator/internal/types"
+)
+
+// feedRefreshChannel is the Postgres NOTIFY channel used to ask a running
+// `agg` process to fetch a feed right away. the payload is the feed URL.
+const feedRefreshChannel = "gator_feed_refresh"
+
+// notifyFeedRefresh asks any listening `agg` process to fetch the feed with
+// the given url immediately instead of waiting for its turn.
+// notifications are best effort: a failure is reported but never aborts
+// the command that triggered it. stores without notifications are skipped
+// silently, their agg fetches the feed in its turn.
+func notifyFeedRefresh(s *types.State, url string) {
+	err := s.Db.NotifyFeedRefresh(context.Background(), url)
+	if err != nil && !errors.Is(err, store.ErrNotSupported) {
+		fmt.Fprintf(os.Stderr, "warning: could not notify agg about feed %v: %v\n", url, err)
+	}
+}
+
+// listenFeedRefresh opens a dedicated connection that LISTENs on the
+// feed refresh channel. the listener reconnects on its own if the
+// connection drops.
+// returns an error if the LISTEN command fails
+func listenFeedRefresh(dbUrl string) (*pq.Listener, error) {
+	// report connection problems without stopping the aggregation loop
+	reportProblem := func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "listener error: %v\n", err)
+		}
+	}
+
+	listener := pq.NewListener(dbUrl, 10*time.Second, time.Minute, reportProblem)
+	if err := listener.Listen(feedRefreshChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("error listening on channel %v: %w", feedRefreshChannel, err)
+	}
+
+	return listener, nil
+}
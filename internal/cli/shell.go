@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/luis-octavius/blog-aggregator/internal/types"
+	"golang.org/x/term"
+)
+
+// shellPrompt is printed before every line read by the shell
+const shellPrompt = "gator> "
+
+// maxShellHistory bounds the lines kept in the shell history file
+const maxShellHistory = 1000
+
+// HandlerShell reads commands line by line and runs each one through
+// the registry, reusing the state (database connection, configuration
+// and output format) of this process. on a terminal it offers line
+// editing, tab completion and a history that is kept across sessions;
+// otherwise it runs the lines of stdin as a script. exit, quit or
+// end of input leave the shell. a failing command prints its error
+// and the shell goes on. `profile use` is refused, as the shell keeps
+// the connection of the profile it started with.
+//
+// returns an error if reading input fails
+func (c *Commands) HandlerShell(s *types.State, cmd Command) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !c.runShellLine(s, scanner.Text()) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	history := loadShellHistory()
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	terminal.History = history
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return c.completeShellLine(s, terminal, line, pos)
+	}
+
+	fmt.Println("gator shell, type help for commands and exit to leave")
+	for {
+		// the terminal is only raw while reading, so commands print normally
+		oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return fmt.Errorf("error setting up the terminal: %w", err)
+		}
+		line, err := terminal.ReadLine()
+		term.Restore(int(os.Stdin.Fd()), oldState)
+
+		if errors.Is(err, io.EOF) {
+			fmt.Println("")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading command: %w", err)
+		}
+
+		if !c.runShellLine(s, line) {
+			return nil
+		}
+	}
+}
+
+// runShellLine runs one line of shell input.
+// returns false if the line asks to leave the shell
+func (c *Commands) runShellLine(s *types.State, line string) bool {
+	words, err := splitWords(line)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return true
+	}
+	if len(words) == 0 {
+		return true
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return false
+	case "shell":
+		fmt.Fprintln(os.Stderr, "already in the gator shell")
+		return true
+	case "profile":
+		// the shell keeps the database and config of the profile it
+		// started with, so switching would only take effect after it
+		if len(words) > 1 && words[1] == "use" {
+			fmt.Fprintln(os.Stderr, "profile use can't switch the profile of a running shell, exit and run `go run . profile use <name>` or start it with `go run . --profile <name> shell`")
+			return true
+		}
+	}
+
+	err = c.Run(s, Command{Name: words[0], Args: words[1:]})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return true
+}
+
+// completeShellLine completes the word before the cursor: a single
+// candidate replaces it, several candidates are listed and their common
+// prefix is filled in
+func (c *Commands) completeShellLine(s *types.State, terminal *term.Terminal, line string, pos int) (string, int, bool) {
+	words := strings.Fields(line[:pos])
+	if len(words) == 0 || strings.HasSuffix(line[:pos], " ") {
+		words = append(words, "")
+	}
+	current := words[len(words)-1]
+
+	candidates := c.complete(s, words)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completion := candidates[0]
+	if len(candidates) == 1 {
+		completion += " "
+	} else {
+		for _, candidate := range candidates[1:] {
+			for !strings.HasPrefix(candidate, completion) {
+				completion = completion[:len(completion)-1]
+			}
+		}
+		if completion == current {
+			fmt.Fprintf(terminal, "%v\n", strings.Join(candidates, "  "))
+			return "", 0, false
+		}
+	}
+
+	start := pos - len(current)
+	newLine := line[:start] + completion + line[pos:]
+	return newLine, start + len(completion), true
+}
+
+// splitWords splits a line into words at spaces, like a shell: single
+// and double quotes group words and a backslash escapes the next rune.
+// returns an error if a quote is not closed
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// shellHistory is the term.History of the shell, saved to a file in the
+// config directory so it survives between sessions
+type shellHistory struct {
+	path  string   // history file, empty if it can't be saved
+	lines []string // oldest first
+}
+
+// loadShellHistory reads the history file. a missing or unreadable
+// file starts an empty history.
+func loadShellHistory() *shellHistory {
+	history := &shellHistory{}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return history
+	}
+	history.path = filepath.Join(configDir, "gator", "shell_history")
+
+	data, err := os.ReadFile(history.path)
+	if err != nil {
+		return history
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history.lines = append(history.lines, line)
+		}
+	}
+	history.trim()
+
+	return history
+}
+
+// Add appends a line to the history and the history file. saving is best
+// effort: a failure never interrupts the shell.
+func (h *shellHistory) Add(line string) {
+	if line == "" || (len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return
+	}
+	h.lines = append(h.lines, line)
+	h.trim()
+
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(h.path, []byte(strings.Join(h.lines, "\n")+"\n"), 0o600)
+}
+
+// Len returns the number of lines in the history
+func (h *shellHistory) Len() int {
+	return len(h.lines)
+}
+
+// At returns a line of the history, 0 being the most recent one
+func (h *shellHistory) At(idx int) string {
+	return h.lines[len(h.lines)-1-idx]
+}
+
+// trim drops the oldest lines beyond maxShellHistory
+func (h *shellHistory) trim() {
+	if len(h.lines) > maxShellHistory {
+		h.lines = h.lines[len(h.lines)-maxShellHistory:]
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: nil},
+		{line: "   ", want: nil},
+		{line: "follow https://example.com/rss", want: []string{"follow", "https://example.com/rss"}},
+		{line: "  addfeed\tname   url  ", want: []string{"addfeed", "name", "url"}},
+		{line: `addfeed "Hacker News" url`, want: []string{"addfeed", "Hacker News", "url"}},
+		{line: `addfeed 'it''s' url`, want: []string{"addfeed", "its", "url"}},
+		{line: `addfeed "say \"hi\"" url`, want: []string{"addfeed", `say "hi"`, "url"}},
+		{line: `addfeed 'a\b' url`, want: []string{"addfeed", `a\b`, "url"}},
+		{line: `addfeed Hacker\ News url`, want: []string{"addfeed", "Hacker News", "url"}},
+		{line: `register ""`, want: []string{"register", ""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitWords(tt.line)
+		if err != nil {
+			t.Errorf("splitWords(%q): %v", tt.line, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitWordsUnterminatedQuote(t *testing.T) {
+	for _, line := range []string{`addfeed "name url`, `addfeed 'name`} {
+		if _, err := splitWords(line); err == nil {
+			t.Errorf("splitWords(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	newTestState(t)
+
+	history := loadShellHistory()
+	history.Add("users")
+	history.Add("users")
+	history.Add("feeds")
+	history.Add("")
+
+	if history.Len() != 2 || history.At(0) != "feeds" || history.At(1) != "users" {
+		t.Errorf("history = %q, want users then feeds", history.lines)
+	}
+
+	// the next shell starts with the saved lines
+	if loaded := loadShellHistory(); !slices.Equal(loaded.lines, history.lines) {
+		t.Errorf("loaded history = %q, want %q", loaded.lines, history.lines)
+	}
+}
+
+func TestRunShellLine(t *testing.T) {
+	s := newTestState(t)
+
+	c := Commands{}
+	c.Register("register", CommandInfo{MinArgs: 1, MaxArgs: 1}, HandlerRegister)
+
+	for _, line := range []string{`register "alice"`, "register bob", "", "shell", `register "unterminated`} {
+		if !c.runShellLine(s, line) {
+			t.Errorf("runShellLine(%q) ended the shell", line)
+		}
+	}
+	// the state outlives each line
+	if s.Config.Current_user_name != "bob" {
+		t.Errorf("current user = %v, want bob", s.Config.Current_user_name)
+	}
+
+	for _, line := range []string{"exit", "quit"} {
+		if c.runShellLine(s, line) {
+			t.Errorf("runShellLine(%q) kept the shell running", line)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupHome points the home and config directories at a temporary
+// directory and clears the environment variables that override the
+// config, returning the home directory
+func setupHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	for _, key := range []string{envConfigPath, envProfile, envDbUrl, envUser, envSession} {
+		t.Setenv(key, "")
+	}
+	return home
+}
+
+// writeJSON writes data as a JSON file, creating its directory
+func writeJSON(t *testing.T, path string, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadLayers(t *testing.T) {
+	home := setupHome(t)
+
+	// the original flat file, a file in the XDG location and one picked
+	// with GATOR_CONFIG
+	legacyPath := filepath.Join(home, configFileName)
+	writeJSON(t, legacyPath, `{"db_url": "legacy-url", "current_user_name": "alice"}`)
+	xdgPath := filepath.Join(home, ".config", "gator", "config.json")
+	writeJSON(t, xdgPath, `{"db_url": "xdg-url"}`)
+	explicitPath := filepath.Join(home, "explicit.json")
+	writeJSON(t, explicitPath, `{"db_url": "explicit-url"}`)
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		overrides Overrides
+		want      Config
+		origins   map[string]string
+	}{
+		{
+			name: "files",
+			want: Config{Db_url: "xdg-url", Current_user_name: "alice"},
+			origins: map[string]string{
+				"db_url":            "file " + xdgPath,
+				"current_user_name": "file " + legacyPath,
+			},
+		},
+		{
+			name: "explicit file over xdg",
+			env:  map[string]string{envConfigPath: explicitPath},
+			want: Config{Db_url: "explicit-url", Current_user_name: "alice"},
+			origins: map[string]string{
+				"db_url": "file " + explicitPath,
+			},
+		},
+		{
+			name: "env over files",
+			env:  map[string]string{envDbUrl: "env-url", envUser: "bob"},
+			want: Config{Db_url: "env-url", Current_user_name: "bob"},
+			origins: map[string]string{
+				"db_url":            "env " + envDbUrl,
+				"current_user_name": "env " + envUser,
+			},
+		},
+		{
+			name:      "flags over env",
+			env:       map[string]string{envDbUrl: "env-url", envUser: "bob"},
+			overrides: Overrides{DbUrl: "flag-url", User: "carol"},
+			want:      Config{Db_url: "flag-url", Current_user_name: "carol"},
+			origins: map[string]string{
+				"db_url":            "flag --db-url",
+				"current_user_name": "flag --user",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Read(tt.overrides)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+
+			if cfg.Db_url != tt.want.Db_url || cfg.Current_user_name != tt.want.Current_user_name {
+				t.Errorf("Read = %+v, want %+v", cfg, tt.want)
+			}
+			for key, origin := range tt.origins {
+				if cfg.origins[key] != origin {
+					t.Errorf("origin of %v = %q, want %q", key, cfg.origins[key], origin)
+				}
+			}
+		})
+	}
+}
+
+func TestReadDefaults(t *testing.T) {
+	setupHome(t)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cfg.Db_url != defaultDbUrl || cfg.Current_user_name != defaultUser {
+		t.Errorf("Read without config files = %+v, want the defaults", cfg)
+	}
+	for _, key := range []string{"db_url", "current_user_name"} {
+		if cfg.origins[key] != "default" {
+			t.Errorf("origin of %v = %q, want default", key, cfg.origins[key])
+		}
+	}
+}
+
+func TestReadProfiles(t *testing.T) {
+	home := setupHome(t)
+	writeJSON(t, filepath.Join(home, ".config", "gator", "config.json"), `{
+		"current_profile": "team",
+		"profiles": {"default": {"db_url": "personal-url"}, "team": {"db_url": "team-url", "current_user_name": "tom"}}
+	}`)
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		overrides   Overrides
+		wantProfile string
+		wantDbUrl   string
+		wantOrigin  string
+	}{
+		{name: "current profile of the file", wantProfile: "team", wantDbUrl: "team-url"},
+		{name: "profile from env", env: map[string]string{envProfile: "default"}, wantProfile: "default", wantDbUrl: "personal-url", wantOrigin: "env " + envProfile},
+		{name: "profile flag over env", env: map[string]string{envProfile: "missing"}, overrides: Overrides{Profile: "default"}, wantProfile: "default", wantDbUrl: "personal-url", wantOrigin: "flag --profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Read(tt.overrides)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if cfg.Profile != tt.wantProfile || cfg.Db_url != tt.wantDbUrl {
+				t.Errorf("Read = profile %v, db_url %v, want %v, %v", cfg.Profile, cfg.Db_url, tt.wantProfile, tt.wantDbUrl)
+			}
+			if tt.wantOrigin != "" && cfg.origins["profile"] != tt.wantOrigin {
+				t.Errorf("origin of profile = %q, want %q", cfg.origins["profile"], tt.wantOrigin)
+			}
+		})
+	}
+
+	if _, err := Read(Overrides{Profile: "missing"}); err == nil {
+		t.Error("Read with an unknown profile succeeded")
+	}
+}
+
+func TestProfileCommands(t *testing.T) {
+	setupHome(t)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if err := cfg.AddProfile("team", "team-url", "tom"); err != nil {
+		t.Fatalf("AddProfile: %v", err)
+	}
+	if err := cfg.AddProfile("team", "other-url", ""); err == nil {
+		t.Error("adding team twice succeeded")
+	}
+	if err := cfg.UseProfile("missing"); err == nil {
+		t.Error("using an unknown profile succeeded")
+	}
+
+	// every command reads the profiles again, like a new process
+	cfg, err = Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := cfg.UseProfile("team"); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+
+	cfg, err = Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cfg.Profile != "team" || cfg.Db_url != "team-url" || cfg.Current_user_name != "tom" {
+		t.Errorf("Read after using team = %+v", cfg)
+	}
+	if names := cfg.ProfileNames(); len(names) != 2 || names[0] != "default" || names[1] != "team" {
+		t.Errorf("ProfileNames = %v, want [default team]", names)
+	}
+
+	if err := cfg.RemoveProfile("team"); err == nil {
+		t.Error("removing the profile in use succeeded")
+	}
+	if err := cfg.UseProfile("default"); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+	cfg, _ = Read(Overrides{})
+	if err := cfg.RemoveProfile("team"); err != nil {
+		t.Fatalf("RemoveProfile: %v", err)
+	}
+	if cfg, _ := Read(Overrides{}); len(cfg.ProfileNames()) != 1 {
+		t.Errorf("ProfileNames after remove = %v, want [default]", cfg.ProfileNames())
+	}
+}
+
+func TestSetUserMigratesFlatFile(t *testing.T) {
+	home := setupHome(t)
+	path := filepath.Join(home, configFileName)
+	writeJSON(t, path, `{"db_url": "legacy-url", "current_user_name": "alice"}`)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := cfg.SetUser("bob"); err != nil {
+		t.Fatalf("SetUser: %v", err)
+	}
+	if cfg.Current_user_name != "bob" {
+		t.Errorf("user after SetUser = %v, want bob", cfg.Current_user_name)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("config file isn't JSON: %v\n%s", err, data)
+	}
+
+	if _, ok := file["db_url"]; ok {
+		t.Errorf("flat db_url kept in the migrated file:\n%s", data)
+	}
+	profile, _ := file["profiles"].(map[string]any)["default"].(map[string]any)
+	if profile["db_url"] != "legacy-url" || profile["current_user_name"] != "bob" {
+		t.Errorf("default profile = %v, want legacy-url and bob", profile)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package config
+
+// lockFile is a no-op on platforms without flock. writes are still
+// atomic, but concurrent updates may overwrite each other.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,46 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package config
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentUpdates(t *testing.T) {
+	setupHome(t)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	// every update reads the file under the lock, so none is lost
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- cfg.AddProfile(fmt.Sprintf("profile-%02d", i), "url", "")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("AddProfile: %v", err)
+		}
+	}
+
+	cfg, err = Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if names := cfg.ProfileNames(); len(names) != n+1 {
+		t.Errorf("%d profiles after %d concurrent adds, want %d: %v", len(names), n, n+1, names)
+	}
+}
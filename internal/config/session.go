@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envSession names the session of the current shell, e.g. after
+// `export GATOR_SESSION=$$` every terminal keeps its own current user.
+// each session leaves a file behind; `trap 'gator session clear' EXIT`
+// removes it when the shell exits, `gator session clear --all` removes
+// every one.
+const envSession = "GATOR_SESSION"
+
+// sessionsLock is the lock file, in the sessions directory, shared by
+// every session file update
+const sessionsLock = ".lock"
+
+// validSession restricts session names to safe file names
+var validSession = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// sessionFile is the content of one session file: the user logged in
+// through that session, by profile, as each profile has its own database
+type sessionFile struct {
+	Users map[string]string `json:"users,omitempty"`
+}
+
+// getSessionFilePath returns the file holding the session named name,
+// $XDG_CONFIG_HOME/gator/sessions/<name>.json.
+// returns an error if the name isn't a plain file name or the config
+// directory cannot be found.
+func getSessionFilePath(name string) (string, error) {
+	if !validSession.MatchString(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid session name %q, use letters, digits, '.', '_' or '-'", name)
+	}
+
+	dir, err := getSessionsDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// getSessionsDir returns the directory holding the session files,
+// $XDG_CONFIG_HOME/gator/sessions.
+// returns an error if the config directory cannot be found
+func getSessionsDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting the config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "gator", "sessions"), nil
+}
+
+// readSession loads a session file. a missing or empty file is an empty
+// session, so a new shell starts out with the user of the config file.
+// returns an error if the file can't be read or contains invalid JSON.
+func readSession(path string) (sessionFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return sessionFile{}, nil
+	}
+	if err != nil {
+		return sessionFile{}, fmt.Errorf("error reading session file: %w", err)
+	}
+
+	var sf sessionFile
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &sf); err != nil {
+			return sessionFile{}, fmt.Errorf("error unmarshaling data from session file %v: %w", path, err)
+		}
+	}
+
+	return sf, nil
+}
+
+// setSessionUser records user as the current user of profile in the
+// session file, holding the lock shared by all session files.
+// returns an error if the session file can't be read or written
+func setSessionUser(path, profile, user string) error {
+	unlock, err := lockFile(filepath.Join(filepath.Dir(path), sessionsLock))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sf, err := readSession(path)
+	if err != nil {
+		return err
+	}
+
+	if sf.Users == nil {
+		sf.Users = map[string]string{}
+	}
+	sf.Users[profile] = user
+
+	return write(path, sf)
+}
+
+// ClearSessions removes the session file of the current session, or with
+// all every session file, and returns the names of the removed sessions.
+// returns an error if all is false and GATOR_SESSION isn't set, or a file
+// can't be removed
+func ClearSessions(all bool) ([]string, error) {
+	dir, err := getSessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if all {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("error listing session files: %w", err)
+		}
+		for _, path := range paths {
+			names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+		}
+	} else {
+		name := os.Getenv(envSession)
+		if name == "" {
+			return nil, fmt.Errorf("no session in use, set %v or use --all", envSession)
+		}
+		if _, err := getSessionFilePath(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	unlock, err := lockFile(filepath.Join(dir, sessionsLock))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var removed []string
+	for _, name := range names {
+		path := filepath.Join(dir, name+".json")
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("error removing session %v: %w", name, err)
+		}
+		removed = append(removed, name)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetUserInSession(t *testing.T) {
+	home := setupHome(t)
+	path := filepath.Join(home, configFileName)
+	writeJSON(t, path, `{"current_user_name": "alice"}`)
+	t.Setenv(envSession, "1234")
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := cfg.SetUser("bob"); err != nil {
+		t.Fatalf("SetUser: %v", err)
+	}
+
+	// the config file keeps its user, the session has its own
+	if cfg, _ := Read(Overrides{}); cfg.Current_user_name != "bob" {
+		t.Errorf("user in the session = %v, want bob", cfg.Current_user_name)
+	}
+	t.Setenv(envSession, "")
+	if cfg, _ := Read(Overrides{}); cfg.Current_user_name != "alice" {
+		t.Errorf("user outside the session = %v, want alice", cfg.Current_user_name)
+	}
+
+	t.Setenv(envSession, "1234")
+	removed, err := ClearSessions(false)
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("ClearSessions = %v, %v, want the session removed", removed, err)
+	}
+	if cfg, _ := Read(Overrides{}); cfg.Current_user_name != "alice" {
+		t.Errorf("user after clearing the session = %v, want alice", cfg.Current_user_name)
+	}
+}
+
+func TestClearSessionsAll(t *testing.T) {
+	setupHome(t)
+
+	for _, name := range []string{"1", "2"} {
+		t.Setenv(envSession, name)
+		cfg, err := Read(Overrides{})
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if err := cfg.SetUser("bob"); err != nil {
+			t.Fatalf("SetUser in session %v: %v", name, err)
+		}
+	}
+
+	t.Setenv(envSession, "")
+	if _, err := ClearSessions(false); err == nil {
+		t.Error("ClearSessions outside a session succeeded")
+	}
+	removed, err := ClearSessions(true)
+	if err != nil || len(removed) != 2 {
+		t.Errorf("ClearSessions(all) = %v, %v, want both sessions removed", removed, err)
+	}
+}
@@ -4,57 +4,156 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 )
 
-// getConfigFilePath returns the full path of the configuration file 
-// located in the user's home directory. 
-// returns the absolute file path or an error if the home directory cannot be found. 
-func getConfigFilePath() (string, error) {
+// getConfigFilePaths returns the config files to load, lowest precedence
+// first, and the file that changes are written to. the candidates are:
+// - ~/.gatorconfig.json, the original location
+// - $XDG_CONFIG_HOME/gator/config.json (~/.config/gator/config.json)
+// - the explicit path from --config or GATOR_CONFIG
+//
+// missing files are left out of the layers. changes go to the explicit
+// path if there is one, otherwise to the highest existing file, otherwise
+// to the XDG location.
+// returns an error if the home or config directory cannot be found.
+func getConfigFilePaths(explicit string) ([]string, string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("error getting the home path: %w", err)
+		return nil, "", fmt.Errorf("error getting the home path: %w", err)
 	}
 
-	configFilePath := path.Join(home, configFileName)
-	return configFilePath, nil
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting the config directory: %w", err)
+	}
+
+	legacyPath := filepath.Join(home, configFileName)
+	xdgPath := filepath.Join(configDir, "gator", "config.json")
+
+	if explicit == "" {
+		explicit = os.Getenv(envConfigPath)
+	}
+
+	var layers []string
+	target := xdgPath
+
+	for _, path := range []string{legacyPath, xdgPath, explicit} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			layers = append(layers, path)
+			target = path
+		}
+	}
+
+	if explicit != "" {
+		target = explicit
+	}
+
+	return layers, target, nil
 }
 
-// write persists the configuration to disk as JSON. 
-// it replaces the entire config file by removing the existing file 
-// and creating a new one to ensure data consistency. 
+// write persists a config file layer (or a session file) to disk as JSON.
+// the data goes to a temporary file in the same directory, which is
+// synced and then renamed over the config file, so a crash leaves either
+// the old or the new file in place and never a partial one.
 // returns error if JSON marshaling fails or any file operation fails.
-func write(cfg Config) error {
-	// Marshal configuration to JSON format 
-	jsonData, err := json.Marshal(cfg)
+func write(configFilePath string, data any) error {
+	// Marshal configuration to JSON format
+	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("error marshaling data: %w", err)
 	}
 
-	// determine config file location 
-	configFilePath, err := getConfigFilePath()
-	if err != nil {
-		return fmt.Errorf("error getting the config file path: %w", err)
+	// make sure the config directory exists, e.g. ~/.config/gator
+	dir := filepath.Dir(configFilePath)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating the config directory: %w", err)
 	}
 
-	// remove existing config file to ensure clean write 
-	// ignore "file not found" errors as the file may not exist yet
-	if err = os.Remove(configFilePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("error removing the config file: %w", err)
+	// create the temporary file next to the config file, as rename
+	// is only atomic within one filesystem
+	file, err := os.CreateTemp(dir, filepath.Base(configFilePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary config file: %w", err)
 	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
 
-	// create new config file with appropriate permissions 
-	file, err := os.Create(configFilePath)
-	if err != nil {
-		return fmt.Errorf("error creating the config file path: %w", err)
+	// keep the permissions of the existing file, new files are only
+	// readable by the user as they may hold database passwords
+	if info, err := os.Stat(configFilePath); err == nil {
+		if err := file.Chmod(info.Mode().Perm()); err != nil {
+			file.Close()
+			return fmt.Errorf("error setting config file permissions: %w", err)
+		}
 	}
-	defer file.Close()
 
-	// write JSON data to file
-	_, err = file.Write(jsonData)
-	if err != nil {
+	// write JSON data to file and flush it to disk
+	if _, err = file.Write(jsonData); err != nil {
+		file.Close()
 		return fmt.Errorf("error writing to config file with json data: %w", err)
 	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("error syncing config file: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("error closing config file: %w", err)
+	}
+
+	// atomically replace the config file
+	if err = os.Rename(tmpPath, configFilePath); err != nil {
+		return fmt.Errorf("error replacing the config file: %w", err)
+	}
+
+	// persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
 
 	return nil
 }
+
+// unknownFields returns the fields of the JSON object in data whose keys
+// are not in known, so files written by newer versions survive a rewrite.
+func unknownFields(data []byte, known ...string) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return fields, nil
+}
+
+// marshalWithUnknown encodes v and adds the unknown fields back next to
+// the known ones. known fields always win over unknown ones.
+func marshalWithUnknown(v any, unknown map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range unknown {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeJSON(t, path, `{"db_url": "old-url"}`)
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := write(path, map[string]string{"db_url": "new-url"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != `{"db_url":"new-url"}` {
+		t.Errorf("config file = %s, %v, want the new url", data, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+		t.Errorf("mode after write = %v, want the old 0640", info.Mode().Perm())
+	}
+
+	// the temporary file was renamed, nothing is left next to the config
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("files after write = %v, want only config.json", entries)
+	}
+}
+
+func TestReadLongFile(t *testing.T) {
+	home := setupHome(t)
+
+	// longer than the fixed buffer the config used to be read into
+	dbUrl := "postgres://gator:" + strings.Repeat("x", 300) + "@localhost:5432/gator"
+	writeJSON(t, filepath.Join(home, configFileName), `{"db_url": "`+dbUrl+`"}`)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cfg.Db_url != dbUrl {
+		t.Errorf("db_url = %q, want all %d bytes", cfg.Db_url, len(dbUrl))
+	}
+}
+
+func TestSetUserKeepsUnknownFields(t *testing.T) {
+	home := setupHome(t)
+	path := filepath.Join(home, configFileName)
+	writeJSON(t, path, `{"db_url": "url", "theme": "dark", "profiles": {"default": {"editor": "vim"}}}`)
+
+	cfg, err := Read(Overrides{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := cfg.SetUser("bob"); err != nil {
+		t.Fatalf("SetUser: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("config file isn't JSON: %v\n%s", err, data)
+	}
+	if file["theme"] != "dark" {
+		t.Errorf("unknown field theme lost:\n%s", data)
+	}
+	profile, _ := file["profiles"].(map[string]any)["default"].(map[string]any)
+	if profile["editor"] != "vim" || profile["current_user_name"] != "bob" {
+		t.Errorf("default profile = %v, want editor kept and bob", profile)
+	}
+}
@@ -34,4 +34,5 @@ type User struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Name      string
+	IsAdmin   bool
 }
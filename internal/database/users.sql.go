@@ -111,14 +111,15 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 }
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
 VALUES (
   $1, 
   $2, 
   $3, 
-  $4
+  $4,
+  NOT EXISTS (SELECT 1 FROM users)
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, is_admin
 `
 
 type CreateUserParams struct {
@@ -128,6 +129,7 @@ type CreateUserParams struct {
 	Name      string
 }
 
+// the first user becomes the admin
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, createUser,
 		arg.ID,
@@ -141,6 +143,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.IsAdmin,
 	)
 	return i, err
 }
@@ -160,6 +163,19 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users 
+WHERE name = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUsers = `-- name: DeleteUsers :exec
 DELETE FROM users
 `
@@ -285,7 +301,7 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, name FROM users 
+SELECT id, created_at, updated_at, name, is_admin FROM users 
 WHERE name = $1 LIMIT 1
 `
 
@@ -297,12 +313,31 @@ func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.IsAdmin,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, name, is_admin FROM users 
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.IsAdmin,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, is_admin FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -319,6 +354,77 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.IsAdmin,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedFollows = `-- name: ListFeedFollows :many
+SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows 
+ORDER BY id
+`
+
+func (q *Queries) ListFeedFollows(ctx context.Context) ([]FeedFollow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFollows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFollow
+	for rows.Next() {
+		var i FeedFollow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeds = `-- name: ListFeeds :many
+SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at FROM feeds 
+ORDER BY id
+`
+
+func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastFetchedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -349,3 +455,151 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.UpdatedAt, arg.ID)
 	return err
 }
+
+const notifyFeedRefresh = `-- name: NotifyFeedRefresh :exec
+SELECT pg_notify('gator_feed_refresh', $1::text)
+`
+
+func (q *Queries) NotifyFeedRefresh(ctx context.Context, url string) error {
+	_, err := q.db.ExecContext(ctx, notifyFeedRefresh, url)
+	return err
+}
+
+const renameUser = `-- name: RenameUser :one
+UPDATE users 
+SET name = $1, updated_at = $2 
+WHERE name = $3
+RETURNING id, created_at, updated_at, name, is_admin
+`
+
+type RenameUserParams struct {
+	NewName   string
+	UpdatedAt time.Time
+	OldName   string
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.NewName, arg.UpdatedAt, arg.OldName)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.IsAdmin,
+	)
+	return i, err
+}
+
+const restoreFeed = `-- name: RestoreFeed :exec
+INSERT INTO feeds (name, url, user_id, created_at, updated_at, last_fetched_at)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+ON CONFLICT (url) DO NOTHING
+`
+
+type RestoreFeedParams struct {
+	Name          string
+	Url           string
+	UserID        uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	LastFetchedAt sql.NullTime
+}
+
+func (q *Queries) RestoreFeed(ctx context.Context, arg RestoreFeedParams) error {
+	_, err := q.db.ExecContext(ctx, restoreFeed,
+		arg.Name,
+		arg.Url,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.LastFetchedAt,
+	)
+	return err
+}
+
+const restoreFeedFollow = `-- name: RestoreFeedFollow :exec
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type RestoreFeedFollowParams struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    int32
+}
+
+func (q *Queries) RestoreFeedFollow(ctx context.Context, arg RestoreFeedFollowParams) error {
+	_, err := q.db.ExecContext(ctx, restoreFeedFollow,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+	return err
+}
+
+const restoreUser = `-- name: RestoreUser :exec
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+ON CONFLICT DO NOTHING
+`
+
+type RestoreUserParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+	IsAdmin   bool
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) error {
+	_, err := q.db.ExecContext(ctx, restoreUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.IsAdmin,
+	)
+	return err
+}
+
+const setUserAdmin = `-- name: SetUserAdmin :execrows
+UPDATE users 
+SET is_admin = $2, updated_at = $3 
+WHERE name = $1
+`
+
+type SetUserAdminParams struct {
+	Name      string
+	IsAdmin   bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserAdmin, arg.Name, arg.IsAdmin, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
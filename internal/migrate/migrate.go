@@ -0,0 +1,191 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+	"github.com/luis-octavius/blog-aggregator/sql/schema"
+	sqliteschema "github.com/luis-octavius/blog-aggregator/sql/sqlite/schema"
+	"github.com/pressly/goose/v3"
+)
+
+// RolesVersion is the schema version whose migration adds users.is_admin.
+// below it users have no roles.
+const RolesVersion = 5
+
+// Status describes one embedded migration and whether it has been
+// applied to the database
+type Status struct {
+	Version   int64
+	File      string
+	Applied   bool
+	AppliedAt string
+}
+
+// newProvider builds a goose provider over the embedded migrations of
+// the backend. it uses the same goose_db_version table as the goose CLI,
+// so databases migrated by hand are recognized.
+func newProvider(db *sql.DB, backend store.Backend) (*goose.Provider, error) {
+	dialect, migrations := goose.DialectPostgres, schema.FS
+	if backend == store.BackendSQLite {
+		dialect, migrations = goose.DialectSQLite3, sqliteschema.FS
+	}
+
+	provider, err := goose.NewProvider(dialect, db, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	return provider, nil
+}
+
+// Up applies every pending migration and returns the applied file names.
+// returns an error if any migration fails
+func Up(ctx context.Context, db *sql.DB, backend store.Backend) ([]string, error) {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error migrating up: %w", err)
+	}
+
+	return resultNames(results), nil
+}
+
+// Down rolls back the most recently applied migration and returns its file name.
+// returns an error if there is nothing to roll back or the migration fails
+func Down(ctx context.Context, db *sql.DB, backend store.Backend) (string, error) {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := provider.Down(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error migrating down: %w", err)
+	}
+
+	return result.Source.Path, nil
+}
+
+// To migrates up or down until the database is at the given version,
+// returning the file names of the migrations that ran.
+// returns an error if the version is unknown or any migration fails
+func To(ctx context.Context, db *sql.DB, backend store.Backend, version int64) ([]string, error) {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	if version < 0 || version > target {
+		return nil, fmt.Errorf("unknown schema version %d (latest is %d)", version, target)
+	}
+
+	// nothing to do, goose would report there is no next version
+	if version == current {
+		return nil, nil
+	}
+
+	var results []*goose.MigrationResult
+	if version > current {
+		results, err = provider.UpTo(ctx, version)
+	} else {
+		results, err = provider.DownTo(ctx, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error migrating to version %d: %w", version, err)
+	}
+
+	return resultNames(results), nil
+}
+
+// Version returns the version the database schema is at, 0 if no
+// migration has been applied.
+// returns an error if the database can't be queried
+func Version(ctx context.Context, db *sql.DB, backend store.Backend) (int64, error) {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	return current, nil
+}
+
+// List reports every embedded migration with its applied state.
+// returns an error if the database can't be queried
+func List(ctx context.Context, db *sql.DB, backend store.Backend) ([]Status, error) {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting migration status: %w", err)
+	}
+
+	var list []Status
+	for _, status := range statuses {
+		s := Status{
+			Version: status.Source.Version,
+			File:    status.Source.Path,
+			Applied: status.State == goose.StateApplied,
+		}
+		if s.Applied {
+			s.AppliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+// Check makes sure the database schema is at the version the embedded
+// migrations expect, so commands fail with a clear message instead of
+// a SQL error about a missing table or column.
+// returns an error describing the mismatch and how to fix it
+func Check(ctx context.Context, db *sql.DB, backend store.Backend) error {
+	provider, err := newProvider(db, backend)
+	if err != nil {
+		return err
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	switch {
+	case current < target:
+		return fmt.Errorf("database schema is at version %d but this binary needs version %d: run `go run . migrate up`", current, target)
+	case current > target:
+		return fmt.Errorf("database schema is at version %d, newer than the %d this binary knows: update the code and run it again", current, target)
+	}
+
+	return nil
+}
+
+// resultNames extracts the migration file names from goose results
+func resultNames(results []*goose.MigrationResult) []string {
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Source.Path)
+	}
+
+	return names
+}
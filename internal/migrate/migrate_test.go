@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+// openSQLite opens an empty SQLite database in a temporary directory
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, backend, err := store.Open("sqlite://" + filepath.Join(t.TempDir(), "gator.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	if backend != store.BackendSQLite {
+		t.Fatalf("backend = %v, want sqlite", backend)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	if err := Check(ctx, db, store.BackendSQLite); err == nil || !strings.Contains(err.Error(), "go run . migrate up") {
+		t.Errorf("Check on an empty database = %v, want a hint to run migrate up", err)
+	}
+
+	applied, err := Up(ctx, db, store.BackendSQLite)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != RolesVersion || applied[0] != "001_users.sql" {
+		t.Errorf("Up applied %v, want every migration from 001_users.sql", applied)
+	}
+	if err := Check(ctx, db, store.BackendSQLite); err != nil {
+		t.Errorf("Check after Up: %v", err)
+	}
+
+	applied, err = Up(ctx, db, store.BackendSQLite)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("second Up = %v, %v, want nothing applied", applied, err)
+	}
+
+	rolledBack, err := Down(ctx, db, store.BackendSQLite)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if rolledBack != "005_users_is_admin.sql" {
+		t.Errorf("Down rolled back %v, want 005_users_is_admin.sql", rolledBack)
+	}
+	if version, err := Version(ctx, db, store.BackendSQLite); err != nil || version != RolesVersion-1 {
+		t.Errorf("Version after Down = %d, %v, want %d", version, err, RolesVersion-1)
+	}
+}
+
+func TestTo(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	migrated, err := To(ctx, db, store.BackendSQLite, 3)
+	if err != nil {
+		t.Fatalf("To 3: %v", err)
+	}
+	if len(migrated) != 3 {
+		t.Errorf("To 3 ran %v, want three migrations", migrated)
+	}
+
+	migrated, err = To(ctx, db, store.BackendSQLite, 1)
+	if err != nil {
+		t.Fatalf("To 1: %v", err)
+	}
+	if len(migrated) != 2 || migrated[0] != "003_feed_follows.sql" {
+		t.Errorf("To 1 ran %v, want 003 and 002 rolled back", migrated)
+	}
+
+	if migrated, err := To(ctx, db, store.BackendSQLite, 1); err != nil || migrated != nil {
+		t.Errorf("To the current version = %v, %v, want nothing to do", migrated, err)
+	}
+	if _, err := To(ctx, db, store.BackendSQLite, 99); err == nil {
+		t.Error("To an unknown version succeeded")
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	if _, err := To(ctx, db, store.BackendSQLite, 2); err != nil {
+		t.Fatalf("To 2: %v", err)
+	}
+
+	statuses, err := List(ctx, db, store.BackendSQLite)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(statuses) != RolesVersion {
+		t.Fatalf("List returned %d migrations, want %d", len(statuses), RolesVersion)
+	}
+	for _, status := range statuses {
+		if applied := status.Version <= 2; status.Applied != applied {
+			t.Errorf("%v applied = %v, want %v", status.File, status.Applied, applied)
+		}
+		if status.Applied && status.AppliedAt == "" {
+			t.Errorf("%v is applied without a time", status.File)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how commands print their results
+type Format string
+
+const (
+	Text  Format = "text"  // the original, human oriented output of each command
+	Table Format = "table" // aligned columns with a header row
+	JSON  Format = "json"  // an indented JSON array of objects
+	YAML  Format = "yaml"  // a YAML sequence of mappings
+)
+
+// ParseFormat returns the format with the given name.
+// returns an error if the name isn't text, table, json or yaml
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case Text, Table, JSON, YAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format %v, use text, table, json or yaml", name)
+	}
+}
+
+// Column is one field of the items in a list: a column of table output
+// and a key of JSON and YAML objects. the header is the upper-cased key.
+type Column[T any] struct {
+	Key   string
+	Value func(item T) any
+}
+
+// List describes how to print a list of items in every format
+type List[T any] struct {
+	Columns []Column[T]
+
+	// Text prints the items in the text format
+	Text func(w io.Writer, items []T)
+}
+
+// Render writes items to w in the given format. JSON and YAML keep the
+// column order and the type of each value; tables print times as RFC 3339
+// and nil values as empty cells.
+// returns an error if encoding or writing fails
+func Render[T any](w io.Writer, format Format, items []T, list List[T]) error {
+	switch format {
+	case Table:
+		return renderTable(w, items, list.Columns)
+	case JSON:
+		return renderJSON(w, items, list.Columns)
+	case YAML:
+		return renderYAML(w, items, list.Columns)
+	default:
+		list.Text(w, items)
+		return nil
+	}
+}
+
+// RenderOne writes the result of a command that acts on a single item:
+// JSON and YAML get one object instead of a list, tables a single row
+// and the text format the item's Text output.
+// returns an error if encoding or writing fails
+func RenderOne[T any](w io.Writer, format Format, item T, list List[T]) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, func(buf *bytes.Buffer) error {
+			return appendJSONObject(buf, item, list.Columns)
+		})
+	case YAML:
+		mapping, err := yamlMapping(item, list.Columns)
+		if err != nil {
+			return err
+		}
+		return writeYAML(w, mapping)
+	default:
+		return Render(w, format, []T{item}, list)
+	}
+}
+
+// renderTable writes a header row and one row per item, aligned with tabs
+func renderTable[T any](w io.Writer, items []T, columns []Column[T]) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = strings.ToUpper(strings.ReplaceAll(column.Key, "_", " "))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = cell(column.Value(item))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("error writing table: %w", err)
+	}
+	return nil
+}
+
+// cell formats a value for a table
+func cell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// renderJSON writes the items as an indented array of objects whose keys
+// are in column order
+func renderJSON[T any](w io.Writer, items []T, columns []Column[T]) error {
+	return writeJSON(w, func(buf *bytes.Buffer) error {
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendJSONObject(buf, item, columns); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	})
+}
+
+// appendJSONObject appends the item to buf as an object whose keys are in
+// column order
+func appendJSONObject[T any](buf *bytes.Buffer, item T, columns []Column[T]) error {
+	buf.WriteByte('{')
+	for j, column := range columns {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column.Key)
+		value, err := json.Marshal(column.Value(item))
+		if err != nil {
+			return fmt.Errorf("error marshaling %v: %w", column.Key, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeJSON indents the JSON built by encode and writes it to w
+func writeJSON(w io.Writer, encode func(buf *bytes.Buffer) error) error {
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return fmt.Errorf("error indenting json: %w", err)
+	}
+	out.WriteByte('\n')
+
+	if _, err := out.WriteTo(w); err != nil {
+		return fmt.Errorf("error writing json: %w", err)
+	}
+	return nil
+}
+
+// renderYAML writes the items as a sequence of mappings whose keys are
+// in column order
+func renderYAML[T any](w io.Writer, items []T, columns []Column[T]) error {
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range items {
+		mapping, err := yamlMapping(item, columns)
+		if err != nil {
+			return err
+		}
+		doc.Content = append(doc.Content, mapping)
+	}
+
+	return writeYAML(w, doc)
+}
+
+// yamlMapping returns the item as a mapping whose keys are in column order
+func yamlMapping[T any](item T, columns []Column[T]) (*yaml.Node, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, column := range columns {
+		var value yaml.Node
+		if err := value.Encode(column.Value(item)); err != nil {
+			return nil, fmt.Errorf("error marshaling %v: %w", column.Key, err)
+		}
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: column.Key},
+			&value,
+		)
+	}
+	return mapping, nil
+}
+
+// writeYAML encodes the document to w
+func writeYAML(w io.Writer, doc *yaml.Node) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error writing yaml: %w", err)
+	}
+	return encoder.Close()
+}
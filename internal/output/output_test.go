@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type feed struct {
+	name    string
+	fetched *time.Time
+	items   int
+}
+
+var feedList = List[feed]{
+	Columns: []Column[feed]{
+		{Key: "name", Value: func(f feed) any { return f.name }},
+		{Key: "last_fetched_at", Value: func(f feed) any { return f.fetched }},
+		{Key: "items", Value: func(f feed) any { return f.items }},
+	},
+	Text: func(w io.Writer, feeds []feed) {
+		for _, f := range feeds {
+			fmt.Fprintf(w, "* %v\n", f.name)
+		}
+	},
+}
+
+func TestRender(t *testing.T) {
+	fetched := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	feeds := []feed{{name: "hn", fetched: &fetched, items: 30}, {name: "go blog"}}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{Text, "* hn\n* go blog\n"},
+		{Table, "NAME     LAST FETCHED AT       ITEMS\n" +
+			"hn       2024-05-01T12:00:00Z  30\n" +
+			"go blog                        0\n"},
+		{JSON, `[
+  {
+    "name": "hn",
+    "last_fetched_at": "2024-05-01T12:00:00Z",
+    "items": 30
+  },
+  {
+    "name": "go blog",
+    "last_fetched_at": null,
+    "items": 0
+  }
+]
+`},
+		{YAML, `- name: hn
+  last_fetched_at: 2024-05-01T12:00:00Z
+  items: 30
+- name: go blog
+  last_fetched_at: null
+  items: 0
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.format, feeds, feedList); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Render =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSON, "[]\n"},
+		{YAML, "[]\n"},
+		{Table, "NAME  LAST FETCHED AT  ITEMS\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, tt.format, nil, feedList); err != nil {
+			t.Fatalf("Render %v: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Render %v of no feeds = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestRenderOne(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{Text, "* hn\n"},
+		{Table, "NAME  LAST FETCHED AT  ITEMS\nhn                     3\n"},
+		{JSON, "{\n  \"name\": \"hn\",\n  \"last_fetched_at\": null,\n  \"items\": 3\n}\n"},
+		{YAML, "name: hn\nlast_fetched_at: null\nitems: 3\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := RenderOne(&buf, tt.format, feed{name: "hn", items: 3}, feedList); err != nil {
+			t.Fatalf("RenderOne %v: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("RenderOne %v = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"text", "table", "json", "yaml"} {
+		if format, err := ParseFormat(name); err != nil || string(format) != name {
+			t.Errorf("ParseFormat(%v) = %v, %v", name, format, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) succeeded")
+	}
+}
@@ -0,0 +1,502 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+)
+
+// Memory is a thread-safe, in-process Store. it enforces the same unique
+// and foreign key constraints as the Postgres schema, including the
+// cascading deletes, so handlers behave the same on both.
+// nothing is persisted: the data lives as long as the value.
+type Memory struct {
+	mu           sync.Mutex
+	users        []database.User
+	feeds        []database.Feed
+	follows      []database.FeedFollow
+	nextFeedID   int32
+	nextFollowID int32
+}
+
+// check Memory implements every Store method at compile time
+var _ Store = (*Memory)(nil)
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		nextFeedID:   1,
+		nextFollowID: 1,
+	}
+}
+
+// CreateUser adds a user, failing with ErrDuplicate if the id or name is taken.
+// the first user becomes the admin
+func (m *Memory) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userConflicts(arg.ID, arg.Name) {
+		return database.User{}, fmt.Errorf("user %v: %w", arg.Name, ErrDuplicate)
+	}
+
+	// the first user becomes the admin
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		IsAdmin:   len(m.users) == 0,
+	}
+	m.users = append(m.users, user)
+	return user, nil
+}
+
+// GetUser finds a user by name
+func (m *Memory) GetUser(ctx context.Context, name string) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+// GetUserByID finds a user by id
+func (m *Memory) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user, ok := m.userByID(id); ok {
+		return user, nil
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+// GetUsers lists every user in creation order
+func (m *Memory) GetUsers(ctx context.Context) ([]database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.users), nil
+}
+
+// DeleteUsers removes every user and, like the ON DELETE CASCADE
+// constraints, their feeds and follows
+func (m *Memory) DeleteUsers(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users = nil
+	m.feeds = nil
+	m.follows = nil
+	return nil
+}
+
+// DeleteUser removes the user with the given name and, like the
+// ON DELETE CASCADE constraints, their feeds and follows.
+// returns the number of users removed, 0 or 1
+func (m *Memory) DeleteUser(ctx context.Context, name string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.users, func(user database.User) bool {
+		return user.Name == name
+	})
+	if i < 0 {
+		return 0, nil
+	}
+	id := m.users[i].ID
+
+	var removedFeeds []int32
+	m.feeds = slices.DeleteFunc(m.feeds, func(feed database.Feed) bool {
+		if feed.UserID == id {
+			removedFeeds = append(removedFeeds, feed.ID)
+			return true
+		}
+		return false
+	})
+	m.follows = slices.DeleteFunc(m.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == id || slices.Contains(removedFeeds, follow.FeedID)
+	})
+	m.users = slices.Delete(m.users, i, i+1)
+	return 1, nil
+}
+
+// RenameUser changes the name of a user, failing with sql.ErrNoRows if
+// there is no such user and ErrDuplicate if the new name is taken
+func (m *Memory) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.users, func(user database.User) bool {
+		return user.Name == arg.OldName
+	})
+	if i < 0 {
+		return database.User{}, sql.ErrNoRows
+	}
+	if arg.NewName != arg.OldName && m.userConflicts(uuid.Nil, arg.NewName) {
+		return database.User{}, fmt.Errorf("user %v: %w", arg.NewName, ErrDuplicate)
+	}
+
+	m.users[i].Name = arg.NewName
+	m.users[i].UpdatedAt = arg.UpdatedAt
+	return m.users[i], nil
+}
+
+// SetUserAdmin grants or revokes the admin role of a user.
+// returns the number of users updated, 0 or 1
+func (m *Memory) SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.users, func(user database.User) bool {
+		return user.Name == arg.Name
+	})
+	if i < 0 {
+		return 0, nil
+	}
+
+	m.users[i].IsAdmin = arg.IsAdmin
+	m.users[i].UpdatedAt = arg.UpdatedAt
+	return 1, nil
+}
+
+// RestoreUser adds a user unless its id or name already exists
+func (m *Memory) RestoreUser(ctx context.Context, arg database.RestoreUserParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userConflicts(arg.ID, arg.Name) {
+		return nil
+	}
+
+	m.users = append(m.users, database.User(arg))
+	return nil
+}
+
+// CreateFeed adds a feed owned by an existing user, failing with
+// ErrDuplicate if the url is taken
+func (m *Memory) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.userByID(arg.UserID); !ok {
+		return database.Feed{}, fmt.Errorf("feed %v references unknown user %v", arg.Url, arg.UserID)
+	}
+	if _, ok := m.feedByUrl(arg.Url); ok {
+		return database.Feed{}, fmt.Errorf("feed %v: %w", arg.Url, ErrDuplicate)
+	}
+
+	feed := database.Feed{
+		ID:        m.nextFeedID,
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	m.nextFeedID++
+	m.feeds = append(m.feeds, feed)
+	return feed, nil
+}
+
+// GetFeeds lists every feed with the name of the user that added it
+func (m *Memory) GetFeeds(ctx context.Context) ([]database.GetFeedsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rows []database.GetFeedsRow
+	for _, feed := range m.feeds {
+		user, _ := m.userByID(feed.UserID)
+		rows = append(rows, database.GetFeedsRow{
+			Name:   feed.Name,
+			Url:    feed.Url,
+			Name_2: user.Name,
+		})
+	}
+	return rows, nil
+}
+
+// GetFeedByUrl finds a feed by url
+func (m *Memory) GetFeedByUrl(ctx context.Context, url string) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.feedByUrl(url); ok {
+		return m.feeds[i], nil
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+// ListFeeds lists every feed ordered by id
+func (m *Memory) ListFeeds(ctx context.Context) ([]database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.feeds), nil
+}
+
+// RestoreFeed adds a feed unless its url already exists
+func (m *Memory) RestoreFeed(ctx context.Context, arg database.RestoreFeedParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.userByID(arg.UserID); !ok {
+		return fmt.Errorf("feed %v references unknown user %v", arg.Url, arg.UserID)
+	}
+	if _, ok := m.feedByUrl(arg.Url); ok {
+		return nil
+	}
+
+	m.feeds = append(m.feeds, database.Feed{
+		ID:            m.nextFeedID,
+		Name:          arg.Name,
+		Url:           arg.Url,
+		UserID:        arg.UserID,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		LastFetchedAt: arg.LastFetchedAt,
+	})
+	m.nextFeedID++
+	return nil
+}
+
+// CreateFeedFollow makes a user follow a feed, failing with ErrDuplicate
+// if the user already follows it
+func (m *Memory) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, feed, err := m.followRefs(arg.UserID, arg.FeedID)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	if m.isFollowing(arg.UserID, arg.FeedID) {
+		return database.CreateFeedFollowRow{}, fmt.Errorf("feed follow %v/%v: %w", user.Name, feed.Url, ErrDuplicate)
+	}
+
+	follow := database.FeedFollow{
+		ID:        m.nextFollowID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	}
+	m.nextFollowID++
+	m.follows = append(m.follows, follow)
+
+	return database.CreateFeedFollowRow{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FeedName:  feed.Name,
+		UserName:  user.Name,
+	}, nil
+}
+
+// GetFeedFollowsForUser lists the feeds followed by the named user
+func (m *Memory) GetFeedFollowsForUser(ctx context.Context, name string) ([]database.GetFeedFollowsForUserRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rows []database.GetFeedFollowsForUserRow
+	for _, follow := range m.follows {
+		user, _ := m.userByID(follow.UserID)
+		if user.Name != name {
+			continue
+		}
+		i, _ := m.feedByID(follow.FeedID)
+		rows = append(rows, database.GetFeedFollowsForUserRow{
+			FeedName: m.feeds[i].Name,
+			UserName: user.Name,
+		})
+	}
+	return rows, nil
+}
+
+// DeleteFeedFollow makes a user stop following a feed
+func (m *Memory) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.follows = slices.DeleteFunc(m.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == arg.UserID && follow.FeedID == arg.FeedID
+	})
+	return nil
+}
+
+// ListFeedFollows lists every feed follow ordered by id
+func (m *Memory) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.follows), nil
+}
+
+// RestoreFeedFollow makes a user follow a feed unless it already does
+func (m *Memory) RestoreFeedFollow(ctx context.Context, arg database.RestoreFeedFollowParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, _, err := m.followRefs(arg.UserID, arg.FeedID); err != nil {
+		return err
+	}
+	if m.isFollowing(arg.UserID, arg.FeedID) {
+		return nil
+	}
+
+	m.follows = append(m.follows, database.FeedFollow{
+		ID:        m.nextFollowID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	})
+	m.nextFollowID++
+	return nil
+}
+
+// GetNextFeedToFetch returns the feed fetched longest ago, never fetched
+// feeds first, with the same ordering as the Postgres query
+func (m *Memory) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.feeds) == 0 {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	next := slices.MinFunc(m.feeds, func(a, b database.Feed) int {
+		// NULLS FIRST
+		if a.LastFetchedAt.Valid != b.LastFetchedAt.Valid {
+			if !a.LastFetchedAt.Valid {
+				return -1
+			}
+			return 1
+		}
+		if a.LastFetchedAt.Valid {
+			if c := a.LastFetchedAt.Time.Compare(b.LastFetchedAt.Time); c != 0 {
+				return c
+			}
+		}
+		if c := a.UpdatedAt.Compare(b.UpdatedAt); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	return next, nil
+}
+
+// MarkFeedFetched records when a feed was last fetched
+func (m *Memory) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.feedByID(arg.ID); ok {
+		m.feeds[i].LastFetchedAt = arg.LastFetchedAt
+		m.feeds[i].UpdatedAt = arg.UpdatedAt
+	}
+	return nil
+}
+
+// NotifyFeedRefresh returns ErrNotSupported: an in-memory store is never
+// shared with another process that could listen for it
+func (m *Memory) NotifyFeedRefresh(ctx context.Context, url string) error {
+	return fmt.Errorf("%w: an in-memory store can't notify a running agg", ErrNotSupported)
+}
+
+// InTx runs fn against the store and restores the previous contents if
+// fn fails. it only provides rollback, not isolation from concurrent
+// writers outside the transaction.
+func (m *Memory) InTx(ctx context.Context, fn func(Store) error) error {
+	m.mu.Lock()
+	snapshot := Memory{
+		users:        slices.Clone(m.users),
+		feeds:        slices.Clone(m.feeds),
+		follows:      slices.Clone(m.follows),
+		nextFeedID:   m.nextFeedID,
+		nextFollowID: m.nextFollowID,
+	}
+	m.mu.Unlock()
+
+	if err := fn(m); err != nil {
+		m.mu.Lock()
+		m.users = snapshot.users
+		m.feeds = snapshot.feeds
+		m.follows = snapshot.follows
+		m.nextFeedID = snapshot.nextFeedID
+		m.nextFollowID = snapshot.nextFollowID
+		m.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// userConflicts reports whether a user with the id or name exists.
+// callers must hold m.mu
+func (m *Memory) userConflicts(id uuid.UUID, name string) bool {
+	return slices.ContainsFunc(m.users, func(user database.User) bool {
+		return user.ID == id || user.Name == name
+	})
+}
+
+// userByID finds a user by id. callers must hold m.mu
+func (m *Memory) userByID(id uuid.UUID) (database.User, bool) {
+	i := slices.IndexFunc(m.users, func(user database.User) bool {
+		return user.ID == id
+	})
+	if i < 0 {
+		return database.User{}, false
+	}
+	return m.users[i], true
+}
+
+// feedByUrl finds the index of a feed by url. callers must hold m.mu
+func (m *Memory) feedByUrl(url string) (int, bool) {
+	i := slices.IndexFunc(m.feeds, func(feed database.Feed) bool {
+		return feed.Url == url
+	})
+	return i, i >= 0
+}
+
+// feedByID finds the index of a feed by id. callers must hold m.mu
+func (m *Memory) feedByID(id int32) (int, bool) {
+	i := slices.IndexFunc(m.feeds, func(feed database.Feed) bool {
+		return feed.ID == id
+	})
+	return i, i >= 0
+}
+
+// followRefs checks the user and feed of a follow exist, like the
+// foreign keys on feed_follows. callers must hold m.mu
+func (m *Memory) followRefs(userID uuid.UUID, feedID int32) (database.User, database.Feed, error) {
+	user, ok := m.userByID(userID)
+	if !ok {
+		return database.User{}, database.Feed{}, fmt.Errorf("feed follow references unknown user %v", userID)
+	}
+	i, ok := m.feedByID(feedID)
+	if !ok {
+		return database.User{}, database.Feed{}, fmt.Errorf("feed follow references unknown feed %v", feedID)
+	}
+	return user, m.feeds[i], nil
+}
+
+// isFollowing reports whether the user already follows the feed.
+// callers must hold m.mu
+func (m *Memory) isFollowing(userID uuid.UUID, feedID int32) bool {
+	return slices.ContainsFunc(m.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == userID && follow.FeedID == feedID
+	})
+}
@@ -0,0 +1,176 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+// createUser adds a user to the store, failing the test on error
+func createUser(t *testing.T, st store.Store, name string) database.User {
+	t.Helper()
+
+	user, err := st.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      name,
+	})
+	if err != nil {
+		t.Fatalf("creating user %v: %v", name, err)
+	}
+	return user
+}
+
+// createFeed adds a feed owned by user and makes the user follow it
+func createFeed(t *testing.T, st store.Store, user database.User, url string) database.Feed {
+	t.Helper()
+	ctx := context.Background()
+
+	feed, err := st.CreateFeed(ctx, database.CreateFeedParams{
+		Name:      url,
+		Url:       url,
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("creating feed %v: %v", url, err)
+	}
+
+	_, err = st.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatalf("following feed %v: %v", url, err)
+	}
+	return feed
+}
+
+func TestMemoryCreateUser(t *testing.T) {
+	st := store.NewMemory()
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	bob := createUser(t, st, "bob")
+	if !alice.IsAdmin || bob.IsAdmin {
+		t.Errorf("only the first user should be an admin, got alice %v and bob %v", alice.IsAdmin, bob.IsAdmin)
+	}
+
+	_, err := st.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Name: "alice"})
+	if !store.IsDuplicate(err) {
+		t.Errorf("creating a second alice: got %v, want a duplicate error", err)
+	}
+
+	got, err := st.GetUserByID(ctx, bob.ID)
+	if err != nil || got.Name != "bob" {
+		t.Errorf("GetUserByID(bob) = %v, %v", got.Name, err)
+	}
+	if _, err := st.GetUser(ctx, "carol"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser(carol): got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryDeleteUserCascades(t *testing.T) {
+	st := store.NewMemory()
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	bob := createUser(t, st, "bob")
+	aliceFeed := createFeed(t, st, alice, "https://alice.example/rss")
+	createFeed(t, st, bob, "https://bob.example/rss")
+
+	// bob also follows alice's feed, that follow must stay
+	_, err := st.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		UserID: bob.ID,
+		FeedID: aliceFeed.ID,
+	})
+	if err != nil {
+		t.Fatalf("following alice's feed: %v", err)
+	}
+
+	deleted, err := st.DeleteUser(ctx, "bob")
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteUser(bob) = %v, %v, want 1 row", deleted, err)
+	}
+
+	feeds, _ := st.ListFeeds(ctx)
+	if len(feeds) != 1 || feeds[0].Url != aliceFeed.Url {
+		t.Errorf("feeds after deleting bob = %v, want only alice's feed", feeds)
+	}
+	follows, _ := st.ListFeedFollows(ctx)
+	if len(follows) != 1 || follows[0].UserID != alice.ID {
+		t.Errorf("follows after deleting bob = %v, want only alice's follow", follows)
+	}
+
+	deleted, err = st.DeleteUser(ctx, "bob")
+	if err != nil || deleted != 0 {
+		t.Errorf("deleting bob again = %v, %v, want 0 rows", deleted, err)
+	}
+}
+
+func TestMemoryRenameUser(t *testing.T) {
+	st := store.NewMemory()
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	createUser(t, st, "bob")
+
+	renamed, err := st.RenameUser(ctx, database.RenameUserParams{NewName: "carol", OldName: "alice"})
+	if err != nil || renamed.ID != alice.ID || renamed.Name != "carol" {
+		t.Errorf("renaming alice = %v, %v", renamed, err)
+	}
+
+	_, err = st.RenameUser(ctx, database.RenameUserParams{NewName: "bob", OldName: "carol"})
+	if !store.IsDuplicate(err) {
+		t.Errorf("renaming carol to bob: got %v, want a duplicate error", err)
+	}
+	_, err = st.RenameUser(ctx, database.RenameUserParams{NewName: "dave", OldName: "alice"})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("renaming a missing user: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryInTxRollsBack(t *testing.T) {
+	st := store.NewMemory()
+	ctx := context.Background()
+
+	createUser(t, st, "alice")
+
+	failure := errors.New("failure")
+	err := st.InTx(ctx, func(qtx store.Store) error {
+		createUser(t, qtx, "bob")
+		if err := qtx.DeleteUsers(ctx); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InTx = %v, want the error of fn", err)
+	}
+
+	users, _ := st.GetUsers(ctx)
+	if len(users) != 1 || users[0].Name != "alice" {
+		t.Errorf("users after rollback = %v, want only alice", users)
+	}
+
+	err = st.InTx(ctx, func(qtx store.Store) error {
+		createUser(t, qtx, "bob")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx = %v", err)
+	}
+	if _, err := st.GetUser(ctx, "bob"); err != nil {
+		t.Errorf("bob after commit: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// Backend names the database engine a db_url points to
+type Backend string
+
+const (
+	BackendPostgres Backend = "postgres"
+	BackendSQLite   Backend = "sqlite"
+)
+
+// sqliteScheme prefixes db_url values that select the SQLite backend,
+// e.g. sqlite://gator.db or sqlite:///home/me/.local/share/gator.db
+const sqliteScheme = "sqlite://"
+
+// Open connects to the database described by dbUrl. urls starting with
+// sqlite:// open (or create) a SQLite file, anything else is handed to
+// the Postgres driver.
+// returns the connection pool and the backend it belongs to, or an error
+// if the driver rejects the url
+func Open(dbUrl string) (*sql.DB, Backend, error) {
+	path, ok := strings.CutPrefix(dbUrl, sqliteScheme)
+	if !ok {
+		db, err := sql.Open("postgres", dbUrl)
+		if err != nil {
+			return nil, "", fmt.Errorf("error opening postgres database: %w", err)
+		}
+		return db, BackendPostgres, nil
+	}
+
+	if path == "" {
+		return nil, "", fmt.Errorf("no file path in sqlite url %v", dbUrl)
+	}
+
+	// enforce the foreign keys the cascades rely on, wait for other
+	// writers instead of failing, and store times in a sortable format
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	dsn := path + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening sqlite database: %w", err)
+	}
+	return db, BackendSQLite, nil
+}
+
+// New returns the Store implementation for the given backend
+func New(db *sql.DB, backend Backend) Store {
+	if backend == BackendSQLite {
+		return NewSQLite(db)
+	}
+	return NewPostgres(db)
+}
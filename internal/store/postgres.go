@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+)
+
+// Postgres is the Store backed by the sqlc generated queries
+type Postgres struct {
+	*database.Queries
+	db *sql.DB
+}
+
+// check Postgres implements every Store method at compile time
+var _ Store = (*Postgres)(nil)
+
+// NewPostgres returns a Store running the sqlc queries against db
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{
+		Queries: database.New(db),
+		db:      db,
+	}
+}
+
+// InTx runs fn inside a database transaction, committing it when fn
+// succeeds and rolling it back otherwise.
+// returns the error from fn or from starting/committing the transaction
+func (p *Postgres) InTx(ctx context.Context, fn func(Store) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = fn(&Postgres{
+		Queries: p.Queries.WithTx(tx),
+		db:      p.db,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
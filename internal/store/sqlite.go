@@ -0,0 +1,280 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/database/sqlitedb"
+)
+
+// SQLite is the Store backed by the sqlc queries in sql/sqlite/queries.
+// it converts the SQLite rows (int64 ids) to the database package types,
+// so handlers can't tell it apart from the Postgres store.
+type SQLite struct {
+	q  *sqlitedb.Queries
+	db *sql.DB
+}
+
+// check SQLite implements every Store method at compile time
+var _ Store = (*SQLite)(nil)
+
+// NewSQLite returns a Store running the SQLite queries against db
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{
+		q:  sqlitedb.New(db),
+		db: db,
+	}
+}
+
+// CreateUser adds a user, the first user becomes the admin
+func (s *SQLite) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	user, err := s.q.CreateUser(ctx, sqlitedb.CreateUserParams(arg))
+	return database.User(user), err
+}
+
+// GetUser finds a user by name
+func (s *SQLite) GetUser(ctx context.Context, name string) (database.User, error) {
+	user, err := s.q.GetUser(ctx, name)
+	return database.User(user), err
+}
+
+// GetUserByID finds a user by id
+func (s *SQLite) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	user, err := s.q.GetUserByID(ctx, id)
+	return database.User(user), err
+}
+
+// GetUsers lists every user in creation order
+func (s *SQLite) GetUsers(ctx context.Context) ([]database.User, error) {
+	users, err := s.q.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []database.User
+	for _, user := range users {
+		items = append(items, database.User(user))
+	}
+	return items, nil
+}
+
+// DeleteUsers removes every user with their feeds and follows
+func (s *SQLite) DeleteUsers(ctx context.Context) error {
+	return s.q.DeleteUsers(ctx)
+}
+
+// DeleteUser removes a user by name with their feeds and follows.
+// returns the number of users removed, 0 or 1
+func (s *SQLite) DeleteUser(ctx context.Context, name string) (int64, error) {
+	return s.q.DeleteUser(ctx, name)
+}
+
+// RenameUser changes the name of a user
+func (s *SQLite) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	user, err := s.q.RenameUser(ctx, sqlitedb.RenameUserParams(arg))
+	return database.User(user), err
+}
+
+// SetUserAdmin promotes or demotes a user.
+// returns the number of users updated, 0 or 1
+func (s *SQLite) SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) (int64, error) {
+	return s.q.SetUserAdmin(ctx, sqlitedb.SetUserAdminParams{
+		IsAdmin:   arg.IsAdmin,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+	})
+}
+
+// RestoreUser adds a user unless its id or name already exists
+func (s *SQLite) RestoreUser(ctx context.Context, arg database.RestoreUserParams) error {
+	return s.q.RestoreUser(ctx, sqlitedb.RestoreUserParams(arg))
+}
+
+// CreateFeed adds a feed for a user
+func (s *SQLite) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	feed, err := s.q.CreateFeed(ctx, sqlitedb.CreateFeedParams(arg))
+	return feedFromSQLite(feed), err
+}
+
+// GetFeeds lists every feed with the name of the user that added it
+func (s *SQLite) GetFeeds(ctx context.Context) ([]database.GetFeedsRow, error) {
+	feeds, err := s.q.GetFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []database.GetFeedsRow
+	for _, feed := range feeds {
+		items = append(items, database.GetFeedsRow(feed))
+	}
+	return items, nil
+}
+
+// GetFeedByUrl finds a feed by url
+func (s *SQLite) GetFeedByUrl(ctx context.Context, url string) (database.Feed, error) {
+	feed, err := s.q.GetFeedByUrl(ctx, url)
+	return feedFromSQLite(feed), err
+}
+
+// ListFeeds lists every feed ordered by id
+func (s *SQLite) ListFeeds(ctx context.Context) ([]database.Feed, error) {
+	feeds, err := s.q.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []database.Feed
+	for _, feed := range feeds {
+		items = append(items, feedFromSQLite(feed))
+	}
+	return items, nil
+}
+
+// RestoreFeed adds a feed unless its url already exists
+func (s *SQLite) RestoreFeed(ctx context.Context, arg database.RestoreFeedParams) error {
+	return s.q.RestoreFeed(ctx, sqlitedb.RestoreFeedParams(arg))
+}
+
+// CreateFeedFollow inserts the follow and reads it back with the feed
+// and user names, as SQLite can't do both in one statement
+func (s *SQLite) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	follow, err := s.q.CreateFeedFollow(ctx, sqlitedb.CreateFeedFollowParams{
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    int64(arg.FeedID),
+	})
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+
+	row, err := s.q.GetFeedFollow(ctx, follow.ID)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, fmt.Errorf("error reading created feed follow: %w", err)
+	}
+
+	return database.CreateFeedFollowRow{
+		ID:        int32(row.ID),
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		UserID:    row.UserID,
+		FeedID:    int32(row.FeedID),
+		FeedName:  row.FeedName,
+		UserName:  row.UserName,
+	}, nil
+}
+
+// GetFeedFollowsForUser lists the feeds followed by the named user
+func (s *SQLite) GetFeedFollowsForUser(ctx context.Context, name string) ([]database.GetFeedFollowsForUserRow, error) {
+	follows, err := s.q.GetFeedFollowsForUser(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []database.GetFeedFollowsForUserRow
+	for _, follow := range follows {
+		items = append(items, database.GetFeedFollowsForUserRow(follow))
+	}
+	return items, nil
+}
+
+// DeleteFeedFollow makes a user stop following a feed
+func (s *SQLite) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	return s.q.DeleteFeedFollow(ctx, sqlitedb.DeleteFeedFollowParams{
+		UserID: arg.UserID,
+		FeedID: int64(arg.FeedID),
+	})
+}
+
+// ListFeedFollows lists every feed follow ordered by id
+func (s *SQLite) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	follows, err := s.q.ListFeedFollows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []database.FeedFollow
+	for _, follow := range follows {
+		items = append(items, database.FeedFollow{
+			ID:        int32(follow.ID),
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    int32(follow.FeedID),
+		})
+	}
+	return items, nil
+}
+
+// RestoreFeedFollow makes a user follow a feed unless it already does
+func (s *SQLite) RestoreFeedFollow(ctx context.Context, arg database.RestoreFeedFollowParams) error {
+	return s.q.RestoreFeedFollow(ctx, sqlitedb.RestoreFeedFollowParams{
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    int64(arg.FeedID),
+	})
+}
+
+// GetNextFeedToFetch finds the feed fetched longest ago, never fetched
+// feeds first
+func (s *SQLite) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	feed, err := s.q.GetNextFeedToFetch(ctx)
+	return feedFromSQLite(feed), err
+}
+
+// MarkFeedFetched records when a feed was last fetched
+func (s *SQLite) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	return s.q.MarkFeedFetched(ctx, sqlitedb.MarkFeedFetchedParams{
+		LastFetchedAt: arg.LastFetchedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		ID:            int64(arg.ID),
+	})
+}
+
+// NotifyFeedRefresh returns ErrNotSupported: SQLite has no LISTEN/NOTIFY,
+// so a running agg can't be told about the feed
+func (s *SQLite) NotifyFeedRefresh(ctx context.Context, url string) error {
+	return fmt.Errorf("%w: sqlite can't notify a running agg", ErrNotSupported)
+}
+
+// InTx runs fn inside a database transaction, committing it when fn
+// succeeds and rolling it back otherwise.
+// returns the error from fn or from starting/committing the transaction
+func (s *SQLite) InTx(ctx context.Context, fn func(Store) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = fn(&SQLite{
+		q:  s.q.WithTx(tx),
+		db: s.db,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// feedFromSQLite converts a SQLite feed row to the shared feed type
+func feedFromSQLite(feed sqlitedb.Feed) database.Feed {
+	return database.Feed{
+		ID:            int32(feed.ID),
+		Name:          feed.Name,
+		Url:           feed.Url,
+		UserID:        feed.UserID,
+		CreatedAt:     feed.CreatedAt,
+		UpdatedAt:     feed.UpdatedAt,
+		LastFetchedAt: feed.LastFetchedAt,
+	}
+}
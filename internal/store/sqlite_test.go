@@ -0,0 +1,169 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/migrate"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+// newSQLite returns a SQLite store on a migrated database in a
+// temporary directory
+func newSQLite(t *testing.T) store.Store {
+	t.Helper()
+
+	db, backend, err := store.Open("sqlite://" + filepath.Join(t.TempDir(), "gator.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := migrate.Up(context.Background(), db, backend); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return store.New(db, backend)
+}
+
+func TestSQLiteUsers(t *testing.T) {
+	st := newSQLite(t)
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	bob := createUser(t, st, "bob")
+	if !alice.IsAdmin || bob.IsAdmin {
+		t.Errorf("only the first user should be an admin, got alice %v and bob %v", alice.IsAdmin, bob.IsAdmin)
+	}
+
+	_, err := st.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), Name: "alice"})
+	if !store.IsDuplicate(err) {
+		t.Errorf("creating a second alice: got %v, want a duplicate error", err)
+	}
+
+	got, err := st.GetUserByID(ctx, bob.ID)
+	if err != nil || got.Name != "bob" {
+		t.Errorf("GetUserByID(bob) = %v, %v", got.Name, err)
+	}
+	if _, err := st.GetUser(ctx, "carol"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser(carol): got %v, want sql.ErrNoRows", err)
+	}
+
+	renamed, err := st.RenameUser(ctx, database.RenameUserParams{NewName: "robert", UpdatedAt: time.Now(), OldName: "bob"})
+	if err != nil || renamed.ID != bob.ID {
+		t.Errorf("renaming bob = %v, %v", renamed, err)
+	}
+	_, err = st.RenameUser(ctx, database.RenameUserParams{NewName: "alice", UpdatedAt: time.Now(), OldName: "robert"})
+	if !store.IsDuplicate(err) {
+		t.Errorf("renaming robert to alice: got %v, want a duplicate error", err)
+	}
+
+	updated, err := st.SetUserAdmin(ctx, database.SetUserAdminParams{Name: "robert", IsAdmin: true, UpdatedAt: time.Now()})
+	if err != nil || updated != 1 {
+		t.Errorf("promoting robert = %v, %v, want 1 row", updated, err)
+	}
+	if got, _ := st.GetUser(ctx, "robert"); !got.IsAdmin {
+		t.Error("robert is not an admin after SetUserAdmin")
+	}
+}
+
+func TestSQLiteDeleteUserCascades(t *testing.T) {
+	st := newSQLite(t)
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	bob := createUser(t, st, "bob")
+	aliceFeed := createFeed(t, st, alice, "https://alice.example/rss")
+	createFeed(t, st, bob, "https://bob.example/rss")
+
+	// the cascades only run with foreign keys enabled by Open
+	deleted, err := st.DeleteUser(ctx, "bob")
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteUser(bob) = %v, %v, want 1 row", deleted, err)
+	}
+
+	feeds, _ := st.ListFeeds(ctx)
+	if len(feeds) != 1 || feeds[0].Url != aliceFeed.Url {
+		t.Errorf("feeds after deleting bob = %v, want only alice's feed", feeds)
+	}
+	follows, _ := st.ListFeedFollows(ctx)
+	if len(follows) != 1 || follows[0].UserID != alice.ID {
+		t.Errorf("follows after deleting bob = %v, want only alice's follow", follows)
+	}
+}
+
+func TestSQLiteFeeds(t *testing.T) {
+	st := newSQLite(t)
+	ctx := context.Background()
+
+	alice := createUser(t, st, "alice")
+	first := createFeed(t, st, alice, "https://first.example/rss")
+	second := createFeed(t, st, alice, "https://second.example/rss")
+
+	_, err := st.CreateFeed(ctx, database.CreateFeedParams{Name: "again", Url: first.Url, UserID: alice.ID})
+	if !store.IsDuplicate(err) {
+		t.Errorf("adding a feed url twice: got %v, want a duplicate error", err)
+	}
+
+	follows, err := st.GetFeedFollowsForUser(ctx, "alice")
+	if err != nil || len(follows) != 2 {
+		t.Errorf("GetFeedFollowsForUser(alice) = %v, %v, want two feeds", follows, err)
+	}
+
+	// never fetched feeds come first, then the one fetched longest ago
+	err = st.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+		LastFetchedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		UpdatedAt:     time.Now(),
+		ID:            first.ID,
+	})
+	if err != nil {
+		t.Fatalf("MarkFeedFetched: %v", err)
+	}
+	next, err := st.GetNextFeedToFetch(ctx)
+	if err != nil || next.ID != second.ID {
+		t.Errorf("GetNextFeedToFetch = %v, %v, want the never fetched %v", next.Url, err, second.Url)
+	}
+
+	err = st.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{UserID: alice.ID, FeedID: first.ID})
+	if err != nil {
+		t.Fatalf("DeleteFeedFollow: %v", err)
+	}
+	if follows, _ := st.GetFeedFollowsForUser(ctx, "alice"); len(follows) != 1 {
+		t.Errorf("follows after unfollowing = %v, want one", follows)
+	}
+}
+
+func TestSQLiteInTxRollsBack(t *testing.T) {
+	st := newSQLite(t)
+	ctx := context.Background()
+
+	createUser(t, st, "alice")
+
+	failure := errors.New("failure")
+	err := st.InTx(ctx, func(qtx store.Store) error {
+		createUser(t, qtx, "bob")
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InTx = %v, want the error of fn", err)
+	}
+
+	users, _ := st.GetUsers(ctx)
+	if len(users) != 1 || users[0].Name != "alice" {
+		t.Errorf("users after rollback = %v, want only alice", users)
+	}
+}
+
+func TestSQLiteNotifyFeedRefresh(t *testing.T) {
+	st := newSQLite(t)
+
+	err := st.NotifyFeedRefresh(context.Background(), "https://example.com/rss")
+	if !errors.Is(err, store.ErrNotSupported) {
+		t.Errorf("NotifyFeedRefresh = %v, want ErrNotSupported", err)
+	}
+}
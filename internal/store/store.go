@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrDuplicate is returned by stores that enforce unique constraints
+// themselves (like Memory) when a row with the same key already exists.
+// the Postgres and SQLite stores return the driver's unique violation
+// error instead, use IsDuplicate to recognize all of them.
+var ErrDuplicate = errors.New("duplicate key")
+
+// ErrNotSupported is returned by stores that can't provide an operation,
+// e.g. NotifyFeedRefresh without Postgres' LISTEN/NOTIFY
+var ErrNotSupported = errors.New("not supported")
+
+// pgUniqueViolation is the Postgres error code of a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// IsDuplicate reports whether err (or an error it wraps) means a row with
+// the same unique key already exists, whichever store returned it.
+func IsDuplicate(err error) bool {
+	if errors.Is(err, ErrDuplicate) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgUniqueViolation
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	return false
+}
+
+// Store is the persistence layer used by the CLI handlers.
+// it mirrors the queries generated by sqlc in the database package, so
+// *database.Queries satisfies every query method and rows are returned
+// with the same database types regardless of the implementation.
+// lookups that find nothing return sql.ErrNoRows, as the Postgres driver does.
+type Store interface {
+	// users
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+	GetUser(ctx context.Context, name string) (database.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+	GetUsers(ctx context.Context) ([]database.User, error)
+	DeleteUsers(ctx context.Context) error
+	DeleteUser(ctx context.Context, name string) (int64, error)
+	RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error)
+	SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) (int64, error)
+	RestoreUser(ctx context.Context, arg database.RestoreUserParams) error
+
+	// feeds
+	CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error)
+	GetFeeds(ctx context.Context) ([]database.GetFeedsRow, error)
+	GetFeedByUrl(ctx context.Context, url string) (database.Feed, error)
+	ListFeeds(ctx context.Context) ([]database.Feed, error)
+	RestoreFeed(ctx context.Context, arg database.RestoreFeedParams) error
+
+	// follows
+	CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error)
+	GetFeedFollowsForUser(ctx context.Context, name string) ([]database.GetFeedFollowsForUserRow, error)
+	DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error
+	ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error)
+	RestoreFeedFollow(ctx context.Context, arg database.RestoreFeedFollowParams) error
+
+	// fetch scheduling
+	GetNextFeedToFetch(ctx context.Context) (database.Feed, error)
+	MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error
+	NotifyFeedRefresh(ctx context.Context, url string) error
+
+	// InTx runs fn against a store whose changes are only kept if fn
+	// returns nil. transactions must not be nested.
+	InTx(ctx context.Context, fn func(Store) error) error
+}
@@ -0,0 +1,29 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
+)
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("connection refused"), false},
+		{fmt.Errorf("user alice: %w", store.ErrDuplicate), true},
+		{fmt.Errorf("error creating user: %w", &pq.Error{Code: "23505"}), true},
+		{&pq.Error{Code: "23503"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := store.IsDuplicate(tt.err); got != tt.want {
+			t.Errorf("IsDuplicate(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
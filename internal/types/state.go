@@ -1,11 +1,17 @@
 package types
 
 import (
+	"database/sql"
+
 	"github.com/luis-octavius/blog-aggregator/internal/config"
-	"github.com/luis-octavius/blog-aggregator/internal/database"
+	"github.com/luis-octavius/blog-aggregator/internal/output"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
 )
 
 type State struct {
-	Db     *database.Queries
-	Config *config.Config
+	Db      store.Store
+	Conn    *sql.DB       // underlying connection pool, used for schema migrations
+	Backend store.Backend // database engine behind Conn
+	Config  *config.Config
+	Output  output.Format // how commands print their results, from --output
 }
@@ -3,75 +3,255 @@ package main
  
 
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/luis-octavius/blog-aggregator/internal/cli"
 	"github.com/luis-octavius/blog-aggregator/internal/config"
-	"github.com/luis-octavius/blog-aggregator/internal/database"
-	_ "github.com/lib/pq"
+	"github.com/luis-octavius/blog-aggregator/internal/migrate"
+	"github.com/luis-octavius/blog-aggregator/internal/output"
+	"github.com/luis-octavius/blog-aggregator/internal/store"
 	"github.com/luis-octavius/blog-aggregator/internal/types"
 )
 
 func main() {
-	// load application configuration from file 	
-	cfg, err := config.Read()
+	// global flags come before the command name and override 
+	// config files and environment variables 
+	globalFlags := flag.NewFlagSet("gator", flag.ContinueOnError)
+	configPath := globalFlags.String("config", "", "path of the config file to use")
+	profileFlag := globalFlags.String("profile", "", "configuration profile to use")
+	dbUrlFlag := globalFlags.String("db-url", "", "database connection URL")
+	userFlag := globalFlags.String("user", "", "user to run the command as")
+	outputFlag := globalFlags.String("output", string(output.Text), "output format: text, table, json or yaml")
+	if err := globalFlags.Parse(os.Args[1:]); err != nil {
+		os.Exit(cli.ExitUsage)
+	}
+
+	outputFormat, err := output.ParseFormat(*outputFlag)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cli.ExitUsage)
+	}
+
+	// load application configuration from every layer 
+	cfg, err := config.Read(config.Overrides{
+		ConfigPath: *configPath,
+		Profile:    *profileFlag,
+		DbUrl:      *dbUrlFlag,
+		User:       *userFlag,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 	
 	dbUrl := cfg.Db_url
 
-	// establish connection with database
-	db, err := sql.Open("postgres", dbUrl)
+	// establish connection with database, Postgres or SQLite 
+	// depending on the url scheme 
+	db, backend, err := store.Open(dbUrl)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	dbQueries := database.New(db)
+	dbStore := store.New(db, backend)
 
 	// initialize application state with dependencies 
 	state := types.State{
-		Db:     dbQueries,
-		Config: &cfg,
+		Db:      dbStore,
+		Conn:    db,
+		Backend: backend,
+		Config:  &cfg,
+		Output:  outputFormat,
 	}
 
 	// CLI command registry - maps command names to handler functions 
-	commandsHandler := cli.Commands{
-		Commands: map[string]func(*types.State, cli.Command) error{},
-	}
+	// and the metadata used for help and argument validation 
+	commandsHandler := cli.Commands{}
 
 	// register available commands 
-	commandsHandler.Register("login", cli.HandlerLogin)
-	commandsHandler.Register("register", cli.HandlerRegister)
-	commandsHandler.Register("reset", cli.HandlerDelete)
-	commandsHandler.Register("users", cli.HandlerUsers)
-	commandsHandler.Register("agg", cli.HandlerAgg)
-	commandsHandler.Register("addfeed", cli.MiddlewareLoggedIn(cli.HandlerAddFeed))
-	commandsHandler.Register("feeds", cli.HandlerListFeeds)
-	commandsHandler.Register("follow", cli.MiddlewareLoggedIn(cli.HandlerFollow))
-	commandsHandler.Register("following", cli.MiddlewareLoggedIn(cli.HandlerFollowing))
-	commandsHandler.Register("unfollow", cli.MiddlewareLoggedIn(cli.HandlerUnfollow))
-
-	args := os.Args
+	commandsHandler.Register("login", cli.CommandInfo{
+		Usage:   "<username>",
+		Summary: "log in as an existing user",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteUsers,
+		Output:  true,
+	}, cli.HandlerLogin)
+	commandsHandler.Register("register", cli.CommandInfo{
+		Usage:   "<username>",
+		Summary: "create a user and log in as it",
+		MinArgs: 1, MaxArgs: 1,
+		Output:  true,
+	}, cli.HandlerRegister)
+	commandsHandler.RegisterLoggedIn("reset", cli.CommandInfo{
+		Usage:   "--yes-i-am-sure",
+		Summary: "delete every user, with their feeds and follows (admins only)",
+		Flags:   cli.ConfirmFlag,
+		Output:  true,
+	}, cli.HandlerDelete)
+	commandsHandler.RegisterLoggedIn("user", cli.CommandInfo{
+		Usage:    "delete [--yes-i-am-sure] <name>|rename <old> <new>|promote <name>|demote <name>",
+		Summary:  "delete, rename, promote or demote a user",
+		MinArgs:  2, MaxArgs: 3,
+		Flags:    cli.ConfirmFlag,
+		Complete: cli.CompleteUserCommand,
+		Output:  true,
+	}, cli.HandlerUser)
+	commandsHandler.Register("users", cli.CommandInfo{
+		Summary: "list users, marking the current one",
+		Output:  true,
+	}, cli.HandlerUsers)
+	commandsHandler.Register("agg", cli.CommandInfo{
+		Usage:   "<time_between_reqs>",
+		Summary: "fetch feeds continuously, e.g. agg 1m",
+		MinArgs: 1, MaxArgs: 1,
+	}, cli.HandlerAgg)
+	commandsHandler.RegisterLoggedIn("addfeed", cli.CommandInfo{
+		Usage:   "<name> <url>",
+		Summary: "add a feed and follow it",
+		MinArgs: 2, MaxArgs: 2,
+		Output:  true,
+	}, cli.HandlerAddFeed)
+	commandsHandler.Register("feeds", cli.CommandInfo{
+		Summary: "list every feed with the user that added it",
+		Output:  true,
+	}, cli.HandlerListFeeds)
+	commandsHandler.RegisterLoggedIn("follow", cli.CommandInfo{
+		Usage:   "<url>",
+		Summary: "follow an existing feed",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteFeedUrls,
+		Output:  true,
+	}, cli.HandlerFollow)
+	commandsHandler.RegisterLoggedIn("following", cli.CommandInfo{
+		Summary: "list the feeds the current user follows",
+		Output:  true,
+	}, cli.HandlerFollowing)
+	commandsHandler.RegisterLoggedIn("unfollow", cli.CommandInfo{
+		Usage:   "<url>",
+		Summary: "stop following a feed",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteFollowedFeedUrls,
+		Output:  true,
+	}, cli.HandlerUnfollow)
+	commandsHandler.Register("refresh", cli.CommandInfo{
+		Usage:   "<url>",
+		Summary: "fetch a feed now (through a running agg on Postgres)",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteFeedUrls,
+		Output:  true,
+	}, cli.HandlerRefresh)
+	commandsHandler.Register("backup", cli.CommandInfo{
+		Usage:   "<file>",
+		Summary: "write users, feeds and follows to an archive",
+		MinArgs: 1, MaxArgs: 1,
+		Output:  true,
+	}, cli.HandlerBackup)
+	commandsHandler.Register("restore", cli.CommandInfo{
+		Usage:   "[--replace --yes-i-am-sure] <file>",
+		Summary: "load an archive written by backup (--replace for admins only)",
+		MinArgs: 1, MaxArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("replace", false, "delete existing users, feeds and follows first")
+			cli.ConfirmFlag(fs)
+		},
+		Output:  true,
+	}, cli.HandlerRestore)
+	commandsHandler.Register("migrate", cli.CommandInfo{
+		Usage:   "up|down [--yes-i-am-sure]|status|to <version> [--yes-i-am-sure]",
+		Summary: "apply, roll back or list schema migrations",
+		MinArgs: 1, MaxArgs: 2,
+		Flags:   cli.ConfirmFlag,
+		Complete: cli.CompleteWords("up", "down", "status", "to"),
+		SkipSchemaCheck: true,
+		Output:  true,
+	}, cli.HandlerMigrate)
+	commandsHandler.Register("config", cli.CommandInfo{
+		Usage:   "show [--origin]",
+		Summary: "print the effective configuration",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteWords("show"),
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("origin", false, "print where each value comes from")
+		},
+		SkipSchemaCheck: true,
+		Output:  true,
+	}, cli.HandlerConfig)
+	commandsHandler.Register("profile", cli.CommandInfo{
+		Usage:   "list|use <name>|add <name> <db_url> [user]|remove <name>",
+		Summary: "manage configuration profiles",
+		MinArgs: 1, MaxArgs: 4,
+		Complete: cli.CompleteProfile,
+		SkipSchemaCheck: true,
+		Output:  true,
+	}, cli.HandlerProfile)
+	commandsHandler.Register("session", cli.CommandInfo{
+		Usage:   "clear [--all]",
+		Summary: "remove the file of this shell's session, or of every session",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteWords("clear"),
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("all", false, "remove every session file")
+		},
+		SkipSchemaCheck: true,
+		Output:  true,
+	}, cli.HandlerSession)
+	commandsHandler.Register("help", cli.CommandInfo{
+		Usage:   "[command]",
+		Summary: "list commands or show the usage of one",
+		MaxArgs: 1,
+		Complete: commandsHandler.CompleteCommands,
+		SkipSchemaCheck: true,
+	}, commandsHandler.HandlerHelp)
+	commandsHandler.Register("completion", cli.CommandInfo{
+		Usage:   "bash|zsh|fish",
+		Summary: "print a shell completion script",
+		MinArgs: 1, MaxArgs: 1,
+		Complete: cli.CompleteWords("bash", "zsh", "fish"),
+		SkipSchemaCheck: true,
+	}, commandsHandler.HandlerCompletion)
+	commandsHandler.Register("shell", cli.CommandInfo{
+		Summary: "run commands interactively over one connection",
+		Output:  true,
+	}, commandsHandler.HandlerShell)
+	commandsHandler.Register("__complete", cli.CommandInfo{
+		MaxArgs: -1,
+		SkipSchemaCheck: true,
+		Output:  true,
+	}, commandsHandler.HandlerComplete)
+
+	// refuse to run against a database whose schema doesn't match 
+	// the embedded migrations, except for commands that fix or 
+	// inspect the setup 
+	commandsHandler.BeforeRun = func(s *types.State, name string, info cli.CommandInfo) error {
+		if info.SkipSchemaCheck {
+			return nil
+		}
+		return migrate.Check(context.Background(), s.Conn, s.Backend)
+	}
+
+	args := globalFlags.Args()
 
 	// validate command-line arguments 
-	if len(args) < 2 {
-		fmt.Println("not enough arguments provided")
-		os.Exit(1)
+	if len(args) < 1 {
+		fmt.Println("not enough arguments provided, run help to list commands")
+		os.Exit(cli.ExitUsage)
 	}
 
 	// parse command from command-line arguments 
 	cmd := cli.Command{
-		Name: args[1],
-		Args: args[2:],
+		Name: args[0],
+		Args: args[1:],
 	}
 
-	// execute requested command 
+	// execute requested command, exiting with the code documented 
+	// in cli.ExitCode when it fails 
 	err = commandsHandler.Run(&state, cmd)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+-- the first user becomes the admin 
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
 VALUES (
   $1, 
   $2, 
   $3, 
-  $4
+  $4,
+  NOT EXISTS (SELECT 1 FROM users)
 )
 RETURNING *;
 
@@ -12,9 +14,28 @@ RETURNING *;
 SELECT * FROM users 
 WHERE name = $1 LIMIT 1;
 
+-- name: GetUserByID :one 
+SELECT * FROM users 
+WHERE id = $1 LIMIT 1;
+
 -- name: DeleteUsers :exec 
 DELETE FROM users;
 
+-- name: DeleteUser :execrows 
+DELETE FROM users 
+WHERE name = $1;
+
+-- name: RenameUser :one 
+UPDATE users 
+SET name = sqlc.arg(new_name), updated_at = sqlc.arg(updated_at) 
+WHERE name = sqlc.arg(old_name)
+RETURNING *;
+
+-- name: SetUserAdmin :execrows 
+UPDATE users 
+SET is_admin = $2, updated_at = $3 
+WHERE name = $1;
+
 -- name: GetUsers :many 
 SELECT * FROM users;
 
@@ -79,3 +100,47 @@ WHERE id = $3;
 SELECT * FROM feeds 
 ORDER BY last_fetched_at NULLS FIRST, updated_at ASC, id ASC   
 LIMIT 1;
+
+-- name: NotifyFeedRefresh :exec 
+SELECT pg_notify('gator_feed_refresh', sqlc.arg(url)::text);
+
+-- name: ListFeeds :many 
+SELECT * FROM feeds 
+ORDER BY id;
+
+-- name: ListFeedFollows :many 
+SELECT * FROM feed_follows 
+ORDER BY id;
+
+-- name: RestoreUser :exec 
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RestoreFeed :exec 
+INSERT INTO feeds (name, url, user_id, created_at, updated_at, last_fetched_at)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+ON CONFLICT (url) DO NOTHING;
+
+-- name: RestoreFeedFollow :exec 
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
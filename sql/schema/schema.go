@@ -0,0 +1,10 @@
+// Package schema embeds the goose migration files so the binary can
+// migrate a database without the goose CLI or the source tree.
+package schema
+
+import "embed"
+
+// FS holds every migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
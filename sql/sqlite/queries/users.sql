@@ -0,0 +1,147 @@
+-- name: CreateUser :one
+-- the first user becomes the admin 
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
+VALUES (
+  ?, 
+  ?, 
+  ?, 
+  ?,
+  NOT EXISTS (SELECT 1 FROM users)
+)
+RETURNING *;
+
+-- name: GetUser :one 
+SELECT * FROM users 
+WHERE name = ? LIMIT 1;
+
+-- name: GetUserByID :one 
+SELECT * FROM users 
+WHERE id = ? LIMIT 1;
+
+-- name: DeleteUsers :exec 
+DELETE FROM users;
+
+-- name: DeleteUser :execrows 
+DELETE FROM users 
+WHERE name = ?;
+
+-- name: RenameUser :one 
+UPDATE users 
+SET name = sqlc.arg(new_name), updated_at = sqlc.arg(updated_at) 
+WHERE name = sqlc.arg(old_name)
+RETURNING *;
+
+-- name: SetUserAdmin :execrows 
+UPDATE users 
+SET is_admin = ?, updated_at = ? 
+WHERE name = ?;
+
+-- name: GetUsers :many 
+SELECT * FROM users;
+
+-- name: CreateFeed :one 
+INSERT INTO feeds (name, url, user_id, created_at, updated_at)
+VALUES (
+  ?,
+  ?, 
+  ?,
+  ?, 
+  ?
+)
+RETURNING *;
+
+-- name: GetFeeds :many 
+SELECT feeds.name, feeds.url, users.name 
+FROM feeds 
+INNER JOIN users 
+ON feeds.user_id = users.id;
+
+-- name: CreateFeedFollow :one 
+-- SQLite doesn't allow INSERT inside a CTE, so the follow is inserted
+-- first and read back with the feed and user names by GetFeedFollow.
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
+VALUES (
+  ?,
+  ?,
+  ?,
+  ?
+)
+RETURNING *;
+
+-- name: GetFeedFollow :one 
+SELECT 
+  feed_follows.*,
+  feeds.name AS feed_name,
+  users.name AS user_name
+FROM feed_follows 
+INNER JOIN feeds ON feed_follows.feed_id = feeds.id 
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.id = ?;
+
+-- name: GetFeedByUrl :one 
+SELECT * FROM feeds 
+WHERE url = ? LIMIT 1;
+
+-- name: GetFeedFollowsForUser :many 
+SELECT 
+  feeds.name AS feed_name,
+  users.name as user_name 
+FROM feed_follows 
+INNER JOIN feeds ON feed_follows.feed_id = feeds.id 
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE users.name = ?; 
+
+-- name: DeleteFeedFollow :exec 
+DELETE FROM feed_follows
+WHERE user_id = ? AND feed_id = ?;
+
+-- name: MarkFeedFetched :exec 
+UPDATE feeds 
+SET last_fetched_at = ?, updated_at = ?
+WHERE id = ?; 
+
+-- name: GetNextFeedToFetch :one 
+SELECT * FROM feeds 
+ORDER BY last_fetched_at NULLS FIRST, updated_at ASC, id ASC   
+LIMIT 1;
+
+-- name: ListFeeds :many 
+SELECT * FROM feeds 
+ORDER BY id;
+
+-- name: ListFeedFollows :many 
+SELECT * FROM feed_follows 
+ORDER BY id;
+
+-- name: RestoreUser :exec 
+INSERT INTO users (id, created_at, updated_at, name, is_admin)
+VALUES (
+  ?,
+  ?,
+  ?,
+  ?,
+  ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: RestoreFeed :exec 
+INSERT INTO feeds (name, url, user_id, created_at, updated_at, last_fetched_at)
+VALUES (
+  ?,
+  ?,
+  ?,
+  ?,
+  ?,
+  ?
+)
+ON CONFLICT (url) DO NOTHING;
+
+-- name: RestoreFeedFollow :exec 
+INSERT INTO feed_follows (created_at, updated_at, user_id, feed_id)
+VALUES (
+  ?,
+  ?,
+  ?,
+  ?
+)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
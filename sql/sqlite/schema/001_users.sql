@@ -0,0 +1,11 @@
+-- +goose Up 
+CREATE TABLE users (
+  id TEXT PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  name TEXT NOT NULL,
+  UNIQUE(name)
+);
+
+-- +goose Down
+DROP TABLE users;
@@ -0,0 +1,13 @@
+-- +goose Up 
+CREATE TABLE feeds (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  url TEXT NOT NULL, 
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE, 
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE(url)
+);
+
+-- +goose Down 
+DROP TABLE feeds;
@@ -0,0 +1,10 @@
+// Package schema embeds the goose migrations of the SQLite backend.
+// they mirror the Postgres migrations in sql/schema version by version.
+package schema
+
+import "embed"
+
+// FS holds every migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS